@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 
@@ -28,6 +29,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// ErrDBClusterNotFound - no OVNDBCluster of the requested DB type exists
+var ErrDBClusterNotFound = errors.New("failed to find DBCluster")
+
 func getDBClusters(
 	ctx context.Context,
 	h *helper.Helper,
@@ -89,7 +93,7 @@ func GetDBClusterByType(
 			return &ovndb, nil
 		}
 	}
-	return nil, fmt.Errorf("failed to find DBCluster of type %s", dbType)
+	return nil, fmt.Errorf("%w of type %s", ErrDBClusterNotFound, dbType)
 }
 
 func getItems(list client.ObjectList) []client.Object {
@@ -0,0 +1,196 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+)
+
+// OVN Condition Types used by API objects.
+const (
+	// OVNControllerHostStateDirReadyCondition Status=True condition which indicates if the
+	// host state directory in use by the OVS/OVN pods matches spec.hostStateDir
+	OVNControllerHostStateDirReadyCondition condition.Type = "HostStateDirReady"
+
+	// OVNControllerConfigRolloutReadyCondition Status=True condition which indicates if the
+	// current config generation got applied on all the nodes
+	OVNControllerConfigRolloutReadyCondition condition.Type = "ConfigRolloutReady"
+
+	// OVNControllerImageRolloutReadyCondition Status=True condition which indicates if the
+	// OVS/OVN pods of all the nodes run the current pod templates
+	OVNControllerImageRolloutReadyCondition condition.Type = "ImageRolloutReady"
+
+	// OVNUpgradeOrderReadyCondition Status=True condition which indicates if the container image
+	// of ovn-northd or of the DBs got deployed, updates are held until ovn-controller got updated
+	OVNUpgradeOrderReadyCondition condition.Type = "UpgradeOrderReady"
+
+	// OVNNorthdActiveReadyCondition Status=True condition which indicates if exactly one
+	// ovn-northd instance holds the SB lock
+	OVNNorthdActiveReadyCondition condition.Type = "ActiveInstanceReady"
+
+	// OVNDBClusterReadyCondition Status=True condition which indicates if the NB and SB
+	// OVNDBClusters exist and published the endpoints the service connects to
+	OVNDBClusterReadyCondition condition.Type = "DBClusterReady"
+
+	// OVNDiagnosticsCompletedCondition Status=True condition which indicates if the commands
+	// of the current generation ran and their output got stored
+	OVNDiagnosticsCompletedCondition condition.Type = "CommandsCompleted"
+
+	// OVNNorthdNBGlobalReadyCondition Status=True condition which indicates if the NB_Global
+	// options managed by the operator got applied and did not drift since
+	OVNNorthdNBGlobalReadyCondition condition.Type = "NBGlobalOptionsReady"
+
+	// OVNNorthdPausedCondition Status=True condition which indicates if all the ovn-northd
+	// instances got paused as requested by spec.paused. It is only set while they are paused
+	// or failed to resume, it replaces ActiveInstanceReady then. It does not affect Ready
+	OVNNorthdPausedCondition condition.Type = "Paused"
+
+	// OVNDBClusterClientConnectionsStableCondition Status=True condition which indicates if the
+	// client connections of the DB cluster did not drop sharply at the last check. It is set once
+	// the connections got checked and does not affect Ready
+	OVNDBClusterClientConnectionsStableCondition condition.Type = "ClientConnectionsStable"
+)
+
+// Common Messages used by API objects.
+const (
+	// OVNControllerHostStateDirReadyInitMessage
+	OVNControllerHostStateDirReadyInitMessage = "HostStateDir not applied"
+
+	// OVNControllerHostStateDirReadyMessage
+	OVNControllerHostStateDirReadyMessage = "HostStateDir %s in use"
+
+	// OVNControllerHostStateDirChangeRefusedMessage
+	OVNControllerHostStateDirChangeRefusedMessage = "HostStateDir change from %s to %s refused while %d node(s) run OVS/OVN pods, " +
+		"existing state is not migrated. Revert spec.hostStateDir or remove the pods from the nodes first"
+
+	// OVNControllerConfigRolloutReadyInitMessage
+	OVNControllerConfigRolloutReadyInitMessage = "Config rollout not started"
+
+	// OVNControllerConfigRolloutReadyMessage
+	OVNControllerConfigRolloutReadyMessage = "Config rolled out to all nodes"
+
+	// OVNControllerConfigRolloutReadyRunningMessage
+	OVNControllerConfigRolloutReadyRunningMessage = "Config rollout in progress, %d of %d node(s) updated"
+
+	// OVNControllerConfigRolloutReadyFailedMessage
+	OVNControllerConfigRolloutReadyFailedMessage = "Config rolled out except on %d failed node(s): %s"
+
+	// OVNControllerConfigRolloutReadyHaltedMessage
+	OVNControllerConfigRolloutReadyHaltedMessage = "Config rollout halted, %d node(s) failed: %s"
+
+	// OVNControllerConfigRolloutReadyErrorMessage
+	OVNControllerConfigRolloutReadyErrorMessage = "Config rollout error occurred %s"
+
+	// OVNControllerImageRolloutReadyInitMessage
+	OVNControllerImageRolloutReadyInitMessage = "Image rollout not started"
+
+	// OVNControllerImageRolloutReadyMessage
+	OVNControllerImageRolloutReadyMessage = "OVS/OVN pods up to date on all nodes"
+
+	// OVNControllerImageRolloutReadyRunningMessage
+	OVNControllerImageRolloutReadyRunningMessage = "Image rollout in %s phase, %d of %d node(s) updated"
+
+	// OVNControllerImageRolloutReadyPausedMessage
+	OVNControllerImageRolloutReadyPausedMessage = "Image rollout paused, unhealthy node(s): %s"
+
+	// OVNControllerImageRolloutReadyErrorMessage
+	OVNControllerImageRolloutReadyErrorMessage = "Image rollout error occurred %s"
+
+	// OVNUpgradeOrderReadyInitMessage
+	OVNUpgradeOrderReadyInitMessage = "Container image not deployed"
+
+	// OVNUpgradeOrderReadyMessage
+	OVNUpgradeOrderReadyMessage = "Container image deployed"
+
+	// OVNUpgradeOrderReadyHeldMessage
+	OVNUpgradeOrderReadyHeldMessage = "Update to %s held until ovn-controller runs OVN %s or later on every chassis"
+
+	// OVNUpgradeOrderReadyVersionMessage
+	OVNUpgradeOrderReadyVersionMessage = "Update to %s held until its OVN version is known"
+
+	// OVNNorthdActiveReadyInitMessage
+	OVNNorthdActiveReadyInitMessage = "Active ovn-northd instance unknown"
+
+	// OVNNorthdActiveReadyMessage
+	OVNNorthdActiveReadyMessage = "ovn-northd instance %s is active"
+
+	// OVNNorthdActiveReadyNoneMessage
+	OVNNorthdActiveReadyNoneMessage = "No ovn-northd instance holds the SB lock"
+
+	// OVNNorthdActiveReadyMultipleMessage
+	OVNNorthdActiveReadyMultipleMessage = "Several ovn-northd instances claim to be active: %s"
+
+	// OVNDBClusterReadyInitMessage
+	OVNDBClusterReadyInitMessage = "DB clusters not checked"
+
+	// OVNDBClusterReadyMessage
+	OVNDBClusterReadyMessage = "DB cluster endpoints available"
+
+	// OVNDBClusterReadyWaitingMessage
+	OVNDBClusterReadyWaitingMessage = "Waiting for the DB clusters: %s"
+
+	// OVNDBClusterReadyErrorMessage
+	OVNDBClusterReadyErrorMessage = "DB cluster error occurred %s"
+
+	// OVNDiagnosticsCompletedInitMessage
+	OVNDiagnosticsCompletedInitMessage = "Commands not run"
+
+	// OVNDiagnosticsCompletedMessage
+	OVNDiagnosticsCompletedMessage = "%d command(s) run, %d failed"
+
+	// OVNDiagnosticsCompletedRefusedMessage
+	OVNDiagnosticsCompletedRefusedMessage = "Commands not allowed for %s: %s"
+
+	// OVNDiagnosticsCompletedNoPodsMessage
+	OVNDiagnosticsCompletedNoPodsMessage = "No running %s pod to run the commands in"
+
+	// OVNDiagnosticsCompletedErrorMessage
+	OVNDiagnosticsCompletedErrorMessage = "Commands error occurred %s"
+
+	// OVNNorthdNBGlobalReadyInitMessage
+	OVNNorthdNBGlobalReadyInitMessage = "NB_Global options not applied"
+
+	// OVNNorthdNBGlobalReadyMessage
+	OVNNorthdNBGlobalReadyMessage = "NB_Global options applied"
+
+	// OVNNorthdNBGlobalReadyRunningMessage
+	OVNNorthdNBGlobalReadyRunningMessage = "NB_Global options job in progress"
+
+	// OVNNorthdNBGlobalReadyErrorMessage
+	OVNNorthdNBGlobalReadyErrorMessage = "NB_Global options job error occurred %s"
+
+	// OVNNorthdNBGlobalReadyDriftMessage
+	OVNNorthdNBGlobalReadyDriftMessage = "NB_Global options changed outside of the operator, applying them again: %s"
+
+	// OVNNorthdPausedMessage
+	OVNNorthdPausedMessage = "%d ovn-northd instance(s) paused"
+
+	// OVNNorthdPausedRunningMessage
+	OVNNorthdPausedRunningMessage = "ovn-northd instance(s) not paused yet: %s"
+
+	// OVNNorthdPausedUnknownMessage
+	OVNNorthdPausedUnknownMessage = "ovn-northd instance(s) could not be queried: %s"
+
+	// OVNNorthdResumeRunningMessage
+	OVNNorthdResumeRunningMessage = "ovn-northd instance(s) still paused: %s"
+
+	// OVNDBClusterClientConnectionsStableMessage
+	OVNDBClusterClientConnectionsStableMessage = "%d client connection(s)"
+
+	// OVNDBClusterClientConnectionsDroppedMessage
+	OVNDBClusterClientConnectionsDroppedMessage = "Client connections dropped from %d to %d since the last check"
+)
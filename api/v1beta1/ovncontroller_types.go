@@ -21,11 +21,13 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
-	// OVNConfigHash - OVNConfigHash key
+	// OVNConfigHash - OVNConfigHash key, generation of the config applied by the ovn-config-agent containers
 	OVNConfigHash = "OvnConfigHash"
 
 	// Container image fall-back defaults
@@ -35,6 +37,9 @@ const (
 	// OVNControllerContainerImage is the fall-back container image for OVNController ovn-controller
 	OVNControllerContainerImage = "quay.io/podified-antelope-centos9/openstack-ovn-controller:current-podified"
 
+	// OVNControllerHostStateDir is the fall-back host directory holding the OVS/OVN state of the nodes
+	OVNControllerHostStateDir = "/var/home/core"
+
 	// ServiceNameOVNController - ovn-controller service name
 	ServiceNameOVNController = "ovn-controller"
 	// TODO: remove when all external consumers switch to ServiceNameOVNController
@@ -67,6 +72,18 @@ type OVNControllerSpecCore struct {
 	// +optional
 	NicMappings map[string]string `json:"nicMappings,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// +optional
+	// NicMappingsConfig - how the interface of a NicMappings physical network is attached to the
+	// OVS pods, keyed by physical network. Mappings without config use a host-device attachment.
+	NicMappingsConfig map[string]NicMappingConfig `json:"nicMappingsConfig,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +optional
+	// Bridges - OVS bridges connecting the chassis to physical networks, in addition to the
+	// br-<physnet> bridges created for NicMappings. Bridges removed from this list are deleted.
+	Bridges []OVSBridge `json:"bridges,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// Resources - Compute Resources required by this service (Limits/Requests).
 	// https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
@@ -81,10 +98,48 @@ type OVNControllerSpecCore struct {
 	// If specified the IP address of this network is used as the OVNEncapIP.
 	NetworkAttachment string `json:"networkAttachment"`
 
+	// +kubebuilder:validation:Optional
+	// EncapNetworkAttachments - additional NetworkAttachment resource names whose IP addresses are
+	// added to ovn-encap-ip for multi-homed tunnelling. The IP of NetworkAttachment stays the default one.
+	EncapNetworkAttachments []string `json:"encapNetworkAttachments,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// TLS - Parameters related to TLS
 	TLS tls.SimpleService `json:"tls,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HostStateDir - host directory under which the OVS/OVN databases, sockets and logs are kept.
+	// A <namespace> subdirectory is created below it (will be set to /var/home/core if empty).
+	// A change is only applied while no OVS/OVN pods are scheduled, as the existing state is not migrated.
+	HostStateDir string `json:"hostStateDir,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DPDK - when set ovs-vswitchd runs the DPDK userspace datapath and br-int and the physical
+	// bridges use the netdev datapath
+	DPDK *OVSDPDKSpec `json:"dpdk,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HardwareOffload - when set ovs-vswitchd offloads the datapath flows to the NICs (TC flower)
+	HardwareOffload *OVSHardwareOffloadSpec `json:"hardwareOffload,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default={}
+	// ConfigRollout - how changes of the OVS/OVN config are rolled out to the nodes
+	ConfigRollout OVNControllerConfigRollout `json:"configRollout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ImageRollout - when set the OVS/OVN DaemonSets use the OnDelete update strategy and the
+	// operator replaces the pods of the nodes running an outdated pod template, e.g. after an image
+	// update, canary nodes first and then in waves. It pauses while the replaced nodes are unhealthy.
+	ImageRollout *OVNControllerImageRollout `json:"imageRollout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// GracefulRestart - when set ovn-controller exits with --restart, keeping its OpenFlow flows
+	// installed, and external-ids:ovn-ofctrl-wait-before-clear is set from the recompute time
+	// measured on each node, so the next ovn-controller computes the flows before replacing them.
+	// Can not be combined with external-ids.ovn-ofctrl-wait-before-clear.
+	GracefulRestart *OVNControllerGracefulRestart `json:"gracefulRestart,omitempty"`
 }
 
 // OVNControllerStatus defines the observed state of OVNController
@@ -107,6 +162,32 @@ type OVNControllerStatus struct {
 	// NetworkAttachments status of the deployment pods
 	NetworkAttachments map[string][]string `json:"networkAttachments,omitempty"`
 
+	// UnmanagedNetworkAttachments - pre-existing NetworkAttachmentDefinitions not owned by this
+	// OVNController which are attached to the OVS pods as they are
+	UnmanagedNetworkAttachments []string `json:"unmanagedNetworkAttachments,omitempty"`
+
+	// HostStateDir - host directory currently used by the OVS/OVN pods
+	HostStateDir string `json:"hostStateDir,omitempty"`
+
+	// Nodes - state reported by the OVS/OVN pods of each node, keyed by node name
+	Nodes map[string]OVNControllerNodeStatus `json:"nodes,omitempty"`
+
+	// ConfigRollout - progress of the rollout of the current config generation
+	ConfigRollout *OVNControllerConfigRolloutStatus `json:"configRollout,omitempty"`
+
+	// ImageRollout - progress of the rollout of the current OVS/OVN pod templates
+	ImageRollout *OVNControllerImageRolloutStatus `json:"imageRollout,omitempty"`
+
+	// ChassisOvnImage - spec.ovnContainerImage once ovn-controller runs it on every chassis
+	ChassisOvnImage string `json:"chassisOvnImage,omitempty"`
+
+	// ChassisOVNVersion - OVN version reported by every chassis running spec.ovnContainerImage,
+	// empty while they differ or some chassis still run another image
+	ChassisOVNVersion string `json:"chassisOVNVersion,omitempty"`
+
+	// UpgradePhase - phase of the OVN upgrade of the namespace
+	UpgradePhase string `json:"upgradePhase,omitempty"`
+
 	//ObservedGeneration - the most recent generation observed for this service. If the observed generation is less than the spec generation, then the controller has not processed the latest changes.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
@@ -158,7 +239,8 @@ type OVSExternalIDs struct {
 
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="geneve"
-	// +kubebuilder:validation:Enum={"geneve","vxlan"}
+	// +kubebuilder:validation:Pattern=`^(geneve|vxlan)(,(geneve|vxlan))?$`
+	// OvnEncapType - comma separated encapsulation types of the chassis, e.g. geneve,vxlan for VTEP interop
 	OvnEncapType string `json:"ovn-encap-type,omitempty"`
 
 	// +kubebuilder:validation:Optional
@@ -168,6 +250,297 @@ type OVSExternalIDs struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=true
 	EnableChassisAsGateway *bool `json:"enable-chassis-as-gateway"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// OvnRemoteProbeInterval - inactivity probe interval, in milliseconds, of the connection to the SB DB (0 disables it)
+	OvnRemoteProbeInterval *int32 `json:"ovn-remote-probe-interval,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// OvnOpenflowProbeInterval - inactivity probe interval, in seconds, of the OpenFlow connection to br-int (0 disables it)
+	OvnOpenflowProbeInterval *int32 `json:"ovn-openflow-probe-interval,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// OvnMonitorAll - monitor all SB DB records instead of only those relevant to the chassis
+	OvnMonitorAll *bool `json:"ovn-monitor-all,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// OvnOfctrlWaitBeforeClear - time, in milliseconds, ovn-controller waits before clearing the flows on startup
+	OvnOfctrlWaitBeforeClear *int32 `json:"ovn-ofctrl-wait-before-clear,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(inherit|[0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])$`
+	// OvnEncapTos - ToS of the tunnel packets, 0-255 or inherit
+	OvnEncapTos string `json:"ovn-encap-tos,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// OvnMatchNorthdVersion - ovn-controller stops processing SB DB updates until ovn-northd runs the same version
+	OvnMatchNorthdVersion *bool `json:"ovn-match-northd-version,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ExtraExternalIDs - additional Open_vSwitch external_ids. Keys set by the operator can not be overridden.
+	ExtraExternalIDs map[string]string `json:"extraExternalIDs,omitempty"`
+}
+
+const (
+	// NicMappingTypeHostDevice - the NIC is moved into the OVS pods
+	NicMappingTypeHostDevice = "host-device"
+	// NicMappingTypeBond - the member NICs are moved into the OVS pods and bonded by bond-cni
+	NicMappingTypeBond = "bond"
+	// NicMappingTypeMacvlan - a macvlan passthru interface on top of the NIC
+	NicMappingTypeMacvlan = "macvlan"
+	// NicMappingTypeSRIOV - a VF allocated by the SR-IOV device plugin
+	NicMappingTypeSRIOV = "sriov"
+)
+
+// NicMappingConfig - attachment of the interface of a NicMappings physical network
+type NicMappingConfig struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"host-device","bond","macvlan","sriov"}
+	// +kubebuilder:default="host-device"
+	// Type - CNI plugin attaching the interface. host-device and macvlan use the NicMappings NIC,
+	// bond uses Members and sriov uses ResourceName.
+	Type string `json:"type,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Members - host NICs bonded into the physical network interface (bond only)
+	Members []string `json:"members,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"balance-rr","active-backup","balance-xor","broadcast","802.3ad","balance-tlb","balance-alb"}
+	// +kubebuilder:default="active-backup"
+	// BondMode - kernel bonding mode (bond only)
+	BondMode string `json:"bondMode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ResourceName - SR-IOV device plugin resource providing the VF, e.g. openshift.io/physnet1 (sriov only)
+	ResourceName string `json:"resourceName,omitempty"`
+}
+
+// OVSDPDKSpec - OVS-DPDK settings, applied to other_config:dpdk-* and pmd-cpu-mask
+type OVSDPDKSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"true","try"}
+	// +kubebuilder:default="true"
+	// DPDKInit - other_config:dpdk-init, with try ovs-vswitchd keeps running if DPDK fails to initialize
+	DPDKInit string `json:"dpdkInit,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0x)?[0-9a-fA-F]+$`
+	// PMDCPUMask - other_config:pmd-cpu-mask, hex mask of the cores running the PMD threads
+	PMDCPUMask string `json:"pmdCPUMask,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0x)?[0-9a-fA-F]+$`
+	// LcoreMask - other_config:dpdk-lcore-mask, hex mask of the cores running the DPDK lcore threads
+	LcoreMask string `json:"lcoreMask,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(,[0-9]+)*$`
+	// SocketMemory - other_config:dpdk-socket-mem, MB of hugepage memory per NUMA node, e.g. 1024,1024
+	SocketMemory string `json:"socketMemory,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"2Mi","1Gi"}
+	// +kubebuilder:default="1Gi"
+	// HugepageSize - size of the hugepages backing the DPDK memory
+	HugepageSize string `json:"hugepageSize,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// Hugepages - amount of hugepage memory requested by ovs-vswitchd, e.g. 4Gi
+	Hugepages resource.Quantity `json:"hugepages"`
+
+	// +kubebuilder:validation:Optional
+	// Devices - DPDK ports, mapping spec.bridges uplink interface names to their dpdk-devargs
+	// (usually the PCI address of a device bound to vfio-pci). These interfaces are not moved
+	// into the OVS pods but accessed through VFIO.
+	Devices map[string]string `json:"devices,omitempty"`
+}
+
+// OVSHardwareOffloadSpec - OVS hardware offload settings, applied to other_config:hw-offload and tc-policy
+type OVSHardwareOffloadSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"none","skip_sw","skip_hw"}
+	// TCPolicy - other_config:tc-policy, the OVS default (none) is used if empty
+	TCPolicy string `json:"tcPolicy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// PhysicalFunctions - host PFs switched to the switchdev eswitch mode, with TC offload enabled,
+	// before ovs-vswitchd starts
+	PhysicalFunctions []string `json:"physicalFunctions,omitempty"`
+}
+
+// OVSBridge - an OVS bridge connecting the chassis to one or more physical networks
+type OVSBridge struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]{0,13}[a-z0-9])?$`
+	// Name - name of the OVS bridge
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// PhysicalNetworks - physical networks mapped to this bridge in ovn-bridge-mappings
+	PhysicalNetworks []string `json:"physicalNetworks"`
+
+	// +kubebuilder:validation:Optional
+	// Uplink - port attaching the bridge to the physical network
+	Uplink *OVSBridgeUplink `json:"uplink,omitempty"`
+}
+
+// OVSBridgeUplink - uplink port of an OVS bridge, a bond when more than one interface is given
+type OVSBridgeUplink struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// Interfaces - host interfaces of the uplink. Each one is moved into the OVS pods
+	// through a host-device NetworkAttachmentDefinition named after the interface.
+	Interfaces []string `json:"interfaces"`
+
+	// +kubebuilder:validation:Optional
+	// BondName - name of the bond port when more than one interface is given (defaults to <bridge>-bond)
+	BondName string `json:"bondName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"active-backup","balance-slb","balance-tcp"}
+	// +kubebuilder:default="active-backup"
+	// BondMode - OVS bond_mode of the bond port
+	BondMode string `json:"bondMode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"off","active","passive"}
+	// +kubebuilder:default="off"
+	// LACP - LACP negotiation of the bond port, balance-tcp requires active or passive
+	LACP string `json:"lacp,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Trunks - VLANs carried tagged by the uplink, all VLANs are allowed if empty
+	Trunks []int32 `json:"trunks,omitempty"`
+}
+
+// GetBondName - returns the name of the uplink port of bridge br when it is a bond
+func (uplink OVSBridgeUplink) GetBondName(br string) string {
+	if uplink.BondName != "" {
+		return uplink.BondName
+	}
+	return br + "-bond"
+}
+
+// OVNControllerConfigRollout - rollout policy of OVS/OVN config changes
+type OVNControllerConfigRollout struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="10%"
+	// +kubebuilder:validation:XIntOrString
+	// MaxParallel - number, or percentage, of nodes applying a new config at the same time. Nodes
+	// which did not report the new config yet count against it, so it also bounds the nodes affected
+	// by a bad config.
+	MaxParallel *intstr.IntOrString `json:"maxParallel,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:XIntOrString
+	// MaxFailed - failure budget, number or percentage of nodes which may fail to apply a new config.
+	// Failed nodes are skipped, the rollout halts once more nodes fail.
+	MaxFailed *intstr.IntOrString `json:"maxFailed,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	// ProgressDeadlineSeconds - time a node has to apply a new config before it is considered failed
+	ProgressDeadlineSeconds int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// OVNControllerConfigRolloutStatus - progress of a config rollout
+type OVNControllerConfigRolloutStatus struct {
+	// Generation - config generation being rolled out
+	Generation string `json:"generation,omitempty"`
+
+	// UpdatedNodes - number of nodes which applied Generation
+	UpdatedNodes int32 `json:"updatedNodes,omitempty"`
+
+	// TotalNodes - number of nodes the config is rolled out to, the ones running the config agent
+	TotalNodes int32 `json:"totalNodes,omitempty"`
+
+	// PendingNodes - nodes asked to apply Generation which did not report it yet, with the time they were asked
+	PendingNodes map[string]metav1.Time `json:"pendingNodes,omitempty"`
+
+	// FailedNodes - nodes which failed to apply Generation, with the reason. They are skipped by the rollout.
+	FailedNodes map[string]string `json:"failedNodes,omitempty"`
+
+	// Halted - the rollout stopped as more nodes than allowed by spec.configRollout.maxFailed failed
+	Halted bool `json:"halted,omitempty"`
+}
+
+// OVNControllerNodeStatus - state reported by the OVS/OVN pods of a node
+type OVNControllerNodeStatus struct {
+	// BridgeDrift - differences between spec.bridges and OVS found, and corrected, by the last configuration run
+	BridgeDrift []string `json:"bridgeDrift,omitempty"`
+
+	// ConfigGeneration - generation of the OVS/OVN config last applied on the node
+	ConfigGeneration string `json:"configGeneration,omitempty"`
+
+	// ConfigError - error of the last failed attempt to apply the OVS/OVN config on the node,
+	// prefixed by the generation it was applying
+	ConfigError string `json:"configError,omitempty"`
+
+	// HardwareOffload - other_config:hw-offload of ovs-vswitchd on the node
+	HardwareOffload string `json:"hardwareOffload,omitempty"`
+
+	// OffloadedFlows - number of datapath flows offloaded to the NICs, when available
+	OffloadedFlows *int64 `json:"offloadedFlows,omitempty"`
+
+	// Degraded - ovn-controller is not ready on the node, e.g. it is not connected to the SB DB
+	Degraded bool `json:"degraded,omitempty"`
+
+	// DegradedReason - why ovn-controller is degraded on the node
+	DegradedReason string `json:"degradedReason,omitempty"`
+
+	// OVNVersion - version of ovn-controller on the node
+	OVNVersion string `json:"ovnVersion,omitempty"`
+
+	// OVNImage - image of the ovn-controller pod of the node
+	OVNImage string `json:"ovnImage,omitempty"`
+
+	// FlowRestore - outcome of the last restore of the OpenFlow flows saved when ovs-vswitchd stopped
+	FlowRestore *OVNControllerFlowRestoreStatus `json:"flowRestore,omitempty"`
+
+	// RecomputeTime - longest flow recompute of ovn-controller measured on the node, with gracefulRestart
+	RecomputeTime *metav1.Duration `json:"recomputeTime,omitempty"`
+
+	// WaitBeforeClear - external-ids:ovn-ofctrl-wait-before-clear set on the node, with gracefulRestart
+	WaitBeforeClear *metav1.Duration `json:"waitBeforeClear,omitempty"`
+
+	// RestartDowntime - time between the last graceful exit of ovn-controller and the next
+	// ovn-controller being ready on the node, accurate to the period of the readiness probe
+	RestartDowntime *metav1.Duration `json:"restartDowntime,omitempty"`
+}
+
+const (
+	// FlowRestoreSucceeded - the saved flows got restored
+	FlowRestoreSucceeded = "Succeeded"
+	// FlowRestoreFailed - the saved flows could not be restored, ovn-controller has to recreate them
+	FlowRestoreFailed = "Failed"
+)
+
+// OVNControllerFlowRestoreStatus - outcome of a restore of the OpenFlow flows after a restart of ovs-vswitchd
+type OVNControllerFlowRestoreStatus struct {
+	// Result - Succeeded or Failed
+	Result string `json:"result"`
+
+	// SavedFlows - number of flows saved when ovs-vswitchd stopped
+	SavedFlows int64 `json:"savedFlows"`
+
+	// RestoredFlows - number of flows once the restore completed
+	RestoredFlows int64 `json:"restoredFlows"`
+
+	// Time - time of the restore
+	Time metav1.Time `json:"time"`
+}
+
+// IsHitless - whether the restart of ovs-vswitchd kept all the flows, the datapath
+// did not need to wait for ovn-controller to recreate them
+func (restore OVNControllerFlowRestoreStatus) IsHitless() bool {
+	return restore.Result == FlowRestoreSucceeded && restore.RestoredFlows >= restore.SavedFlows
 }
 
 // RbacConditionsSet - set the conditions for the rbac object
@@ -184,3 +557,85 @@ func (instance OVNController) RbacNamespace() string {
 func (instance OVNController) RbacResourceName() string {
 	return "ovncontroller-" + instance.Name
 }
+
+// OVNControllerImageRollout - canary rollout policy of the OVS/OVN pod templates
+type OVNControllerImageRollout struct {
+	// +kubebuilder:validation:Optional
+	// CanaryNodeSelector - labels of the canary nodes, which are updated first as a single wave.
+	// The canary phase is skipped when no node with outdated pods matches.
+	CanaryNodeSelector map[string]string `json:"canaryNodeSelector,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="25%"
+	// +kubebuilder:validation:XIntOrString
+	// WaveSize - number, or percentage, of nodes updated in each wave after the canary phase
+	WaveSize *intstr.IntOrString `json:"waveSize,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=0
+	// MinHealthySeconds - time the pods of the nodes of a wave have to be ready, without any
+	// reported failure, before the next wave starts
+	MinHealthySeconds *int32 `json:"minHealthySeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	// ProgressDeadlineSeconds - time the nodes of a wave have to become healthy before the rollout
+	// is reported as paused
+	ProgressDeadlineSeconds int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+const (
+	// ImageRolloutPhaseCanary - the canary nodes are updated
+	ImageRolloutPhaseCanary = "Canary"
+	// ImageRolloutPhaseWaves - the remaining nodes are updated in waves
+	ImageRolloutPhaseWaves = "Waves"
+	// ImageRolloutPhaseComplete - all the nodes run the current pod templates
+	ImageRolloutPhaseComplete = "Complete"
+)
+
+// OVNControllerImageRolloutStatus - progress of a canary rollout
+type OVNControllerImageRolloutStatus struct {
+	// Revision - hash of the pod templates being rolled out
+	Revision string `json:"revision,omitempty"`
+
+	// Phase - Canary, Waves or Complete
+	Phase string `json:"phase,omitempty"`
+
+	// UpdatedNodes - number of nodes running the current pod templates
+	UpdatedNodes int32 `json:"updatedNodes,omitempty"`
+
+	// TotalNodes - number of nodes running OVS/OVN pods
+	TotalNodes int32 `json:"totalNodes,omitempty"`
+
+	// WaveNodes - nodes of the current wave, their pods got replaced
+	WaveNodes []string `json:"waveNodes,omitempty"`
+
+	// WaveStartTime - time the pods of the current wave got replaced
+	WaveStartTime *metav1.Time `json:"waveStartTime,omitempty"`
+
+	// UnhealthyNodes - nodes of the current wave which are not healthy yet, with the reason
+	UnhealthyNodes map[string]string `json:"unhealthyNodes,omitempty"`
+
+	// Paused - the nodes of the current wave did not become healthy within
+	// spec.imageRollout.progressDeadlineSeconds, no further wave is started until they do
+	Paused bool `json:"paused,omitempty"`
+}
+
+// OVNControllerGracefulRestart - how the OpenFlow flows are kept while ovn-controller restarts
+type OVNControllerGracefulRestart struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1000
+	// +kubebuilder:validation:Minimum=1
+	// MinWaitBeforeClear - lower bound, in milliseconds, of ovn-ofctrl-wait-before-clear. It is
+	// also used until a recompute of ovn-controller got measured on the node.
+	MinWaitBeforeClear int32 `json:"minWaitBeforeClear,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=60000
+	// +kubebuilder:validation:Minimum=1
+	// MaxWaitBeforeClear - upper bound, in milliseconds, of ovn-ofctrl-wait-before-clear, which
+	// is set to twice the longest recompute measured on the node
+	MaxWaitBeforeClear int32 `json:"maxWaitBeforeClear,omitempty"`
+}
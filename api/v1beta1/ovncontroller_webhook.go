@@ -17,7 +17,20 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -71,7 +84,340 @@ func (spec *OVNControllerSpec) Default() {
 
 // Default - set defaults for this OVNController core spec (this version is called by OpenStackControlplane webhooks)
 func (spec *OVNControllerSpecCore) Default() {
-	// nothing here yet
+	if spec.HostStateDir == "" {
+		spec.HostStateDir = OVNControllerHostStateDir
+	}
+}
+
+// ValidateCreate - validate the OVNController core spec (this version is called by OpenStackControlplane webhooks)
+func (spec *OVNControllerSpecCore) ValidateCreate(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, validateHostStateDir(spec.HostStateDir, basePath.Child("hostStateDir"))...)
+	allErrs = append(allErrs, spec.validateNicMappingsConfig(basePath.Child("nicMappingsConfig"))...)
+	allErrs = append(allErrs, spec.validateBridges(basePath.Child("bridges"))...)
+	allErrs = append(allErrs, spec.validateDPDK(basePath.Child("dpdk"))...)
+	allErrs = append(allErrs, spec.validateHardwareOffload(basePath.Child("hardwareOffload"))...)
+	allErrs = append(allErrs, spec.validateEncap(basePath)...)
+	allErrs = append(allErrs, spec.ConfigRollout.validate(basePath.Child("configRollout"))...)
+	if spec.ImageRollout != nil {
+		allErrs = append(allErrs, spec.ImageRollout.validate(basePath.Child("imageRollout"))...)
+	}
+	allErrs = append(allErrs, spec.validateGracefulRestart(basePath)...)
+	allErrs = append(allErrs, spec.ExternalIDS.validateExtraExternalIDs(basePath.Child("external-ids", "extraExternalIDs"))...)
+
+	return allErrs
+}
+
+// ValidateUpdate - validate the OVNController core spec update (this version is called by OpenStackControlplane webhooks)
+func (spec *OVNControllerSpecCore) ValidateUpdate(old OVNControllerSpecCore, basePath *field.Path) (admission.Warnings, field.ErrorList) {
+	var warn admission.Warnings
+
+	if old.HostStateDir != "" && spec.HostStateDir != old.HostStateDir {
+		warn = append(warn, fmt.Sprintf(
+			"%s changed from %s to %s: the change is only applied while no OVS/OVN pods are scheduled",
+			basePath.Child("hostStateDir"), old.HostStateDir, spec.HostStateDir))
+	}
+
+	return warn, spec.ValidateCreate(basePath)
+}
+
+// validateNicMappingsConfig - each config must belong to a NicMappings entry and
+// carry the fields its type requires
+func (spec *OVNControllerSpecCore) validateNicMappingsConfig(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for physNet, cfg := range spec.NicMappingsConfig {
+		cfgPath := fldPath.Key(physNet)
+		if _, ok := spec.NicMappings[physNet]; !ok {
+			allErrs = append(allErrs, field.Invalid(cfgPath, physNet, "physical network is not defined in nicMappings"))
+		}
+		switch cfg.Type {
+		case NicMappingTypeBond:
+			if len(cfg.Members) < 1 {
+				allErrs = append(allErrs, field.Required(cfgPath.Child("members"), "bond requires member NICs"))
+			}
+		case NicMappingTypeSRIOV:
+			if cfg.ResourceName == "" {
+				allErrs = append(allErrs, field.Required(cfgPath.Child("resourceName"), "sriov requires a device plugin resource"))
+			}
+		}
+		if cfg.Type != NicMappingTypeBond && len(cfg.Members) > 0 {
+			allErrs = append(allErrs, field.Forbidden(cfgPath.Child("members"), "only allowed for bond"))
+		}
+		if cfg.Type != NicMappingTypeSRIOV && cfg.ResourceName != "" {
+			allErrs = append(allErrs, field.Forbidden(cfgPath.Child("resourceName"), "only allowed for sriov"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateBridges - bridges, physical networks and uplink interfaces must not be used twice,
+// neither within spec.bridges nor by the br-<physnet> bridges of NicMappings. The uplink
+// interfaces name their NetworkAttachmentDefinitions, which must not clash with the ones of
+// NicMappings nor with the network attachments of the service.
+func (spec *OVNControllerSpecCore) validateBridges(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	bridges := map[string]bool{spec.ExternalIDS.OvnBridge: true}
+	physNets := map[string]bool{}
+	interfaces := map[string]bool{}
+	nadNames := map[string]bool{spec.NetworkAttachment: true}
+	for _, nad := range spec.EncapNetworkAttachments {
+		nadNames[nad] = true
+	}
+	for physNet, nic := range spec.NicMappings {
+		bridges["br-"+physNet] = true
+		physNets[physNet] = true
+		nadNames[physNet] = true
+		interfaces[nic] = true
+		for _, member := range spec.NicMappingsConfig[physNet].Members {
+			interfaces[member] = true
+		}
+	}
+
+	for i, br := range spec.Bridges {
+		brPath := fldPath.Index(i)
+		if bridges[br.Name] {
+			allErrs = append(allErrs, field.Duplicate(brPath.Child("name"), br.Name))
+		}
+		bridges[br.Name] = true
+
+		for j, physNet := range br.PhysicalNetworks {
+			if physNets[physNet] {
+				allErrs = append(allErrs, field.Duplicate(brPath.Child("physicalNetworks").Index(j), physNet))
+			}
+			physNets[physNet] = true
+		}
+
+		if br.Uplink == nil {
+			continue
+		}
+		uplinkPath := brPath.Child("uplink")
+		for j, iface := range br.Uplink.Interfaces {
+			ifacePath := uplinkPath.Child("interfaces").Index(j)
+			// DPDK devices get no NetworkAttachmentDefinition
+			isDPDK := spec.DPDK != nil && spec.DPDK.Devices[iface] != ""
+			if interfaces[iface] {
+				allErrs = append(allErrs, field.Duplicate(ifacePath, iface))
+			} else if nadNames[iface] && !isDPDK {
+				allErrs = append(allErrs, field.Invalid(ifacePath, iface,
+					"NetworkAttachmentDefinition name already used by a physical network or network attachment"))
+			}
+			interfaces[iface] = true
+			if !isDPDK {
+				nadNames[iface] = true
+			}
+			// the interface name is used as NetworkAttachmentDefinition and pod interface name
+			if errs := validation.IsDNS1123Label(iface); len(errs) != 0 || len(iface) > 15 {
+				allErrs = append(allErrs, field.Invalid(ifacePath, iface,
+					"must be a lowercase RFC 1123 label of at most 15 characters"))
+			}
+		}
+		if len(br.Uplink.Interfaces) > 1 && br.Uplink.BondMode == "balance-tcp" &&
+			(br.Uplink.LACP == "" || br.Uplink.LACP == "off") {
+			allErrs = append(allErrs, field.Invalid(uplinkPath.Child("lacp"), br.Uplink.LACP,
+				"balance-tcp bonds require LACP to be active or passive"))
+		}
+		for j, vlan := range br.Uplink.Trunks {
+			if vlan < 0 || vlan > 4095 {
+				allErrs = append(allErrs, field.Invalid(uplinkPath.Child("trunks").Index(j), vlan,
+					"must be a VLAN ID between 0 and 4095"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateDPDK - DPDK needs hugepages and its devices must be spec.bridges uplink interfaces
+func (spec *OVNControllerSpecCore) validateDPDK(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.DPDK == nil {
+		return allErrs
+	}
+	if spec.DPDK.Hugepages.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hugepages"), spec.DPDK.Hugepages.String(),
+			"DPDK requires hugepages"))
+	}
+	// kubernetes only accepts hugepages together with a cpu or memory request
+	hasCPUOrMemory := false
+	for _, resources := range []corev1.ResourceList{spec.Resources.Requests, spec.Resources.Limits} {
+		if _, ok := resources[corev1.ResourceCPU]; ok {
+			hasCPUOrMemory = true
+		}
+		if _, ok := resources[corev1.ResourceMemory]; ok {
+			hasCPUOrMemory = true
+		}
+	}
+	if !hasCPUOrMemory {
+		allErrs = append(allErrs, field.Required(fldPath.Child("hugepages"),
+			"hugepages require a cpu or memory request in spec.resources"))
+	}
+
+	uplinkInterfaces := map[string]bool{}
+	for _, br := range spec.Bridges {
+		if br.Uplink != nil {
+			for _, iface := range br.Uplink.Interfaces {
+				uplinkInterfaces[iface] = true
+			}
+		}
+	}
+	for iface, devargs := range spec.DPDK.Devices {
+		if !uplinkInterfaces[iface] {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("devices").Key(iface), iface,
+				"must be an uplink interface of spec.bridges"))
+		}
+		if devargs == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("devices").Key(iface), "dpdk-devargs of the port"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateHardwareOffload - offload is not combined with DPDK and the PFs must be valid interface names
+func (spec *OVNControllerSpecCore) validateHardwareOffload(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.HardwareOffload == nil {
+		return allErrs
+	}
+	if spec.DPDK != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "hardware offload can not be used together with dpdk"))
+	}
+	seen := map[string]bool{}
+	for i, pf := range spec.HardwareOffload.PhysicalFunctions {
+		pfPath := fldPath.Child("physicalFunctions").Index(i)
+		if seen[pf] {
+			allErrs = append(allErrs, field.Duplicate(pfPath, pf))
+		}
+		seen[pf] = true
+		if len(pf) == 0 || len(pf) > 15 || strings.ContainsAny(pf, "/ ,;:") {
+			allErrs = append(allErrs, field.Invalid(pfPath, pf, "must be a host interface name of at most 15 characters"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateEncap - encap types must be unique and each encap network attachment must provide
+// a distinct encap IP, so it can not be the default NetworkAttachment nor a physical network
+func (spec *OVNControllerSpecCore) validateEncap(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	encapTypes := strings.Split(spec.ExternalIDS.OvnEncapType, ",")
+	if len(encapTypes) == 2 && encapTypes[0] == encapTypes[1] {
+		allErrs = append(allErrs, field.Duplicate(basePath.Child("external-ids", "ovn-encap-type"), encapTypes[1]))
+	}
+
+	fldPath := basePath.Child("encapNetworkAttachments")
+	seen := map[string]bool{}
+	for i, netAtt := range spec.EncapNetworkAttachments {
+		netAttPath := fldPath.Index(i)
+		switch {
+		case seen[netAtt]:
+			allErrs = append(allErrs, field.Duplicate(netAttPath, netAtt))
+		case netAtt == spec.NetworkAttachment:
+			allErrs = append(allErrs, field.Invalid(netAttPath, netAtt, "is already the networkAttachment of the default encap IP"))
+		case spec.NicMappings[netAtt] != "":
+			allErrs = append(allErrs, field.Invalid(netAttPath, netAtt, "is a physical network of nicMappings"))
+		}
+		seen[netAtt] = true
+	}
+	if len(spec.EncapNetworkAttachments) > 0 && spec.NetworkAttachment == "" {
+		allErrs = append(allErrs, field.Required(basePath.Child("networkAttachment"),
+			"the default encap IP has to come from a networkAttachment when encapNetworkAttachments are set"))
+	}
+
+	return allErrs
+}
+
+// validate - maxParallel and maxFailed must be non negative numbers or percentages, maxParallel can not be 0
+func (rollout *OVNControllerConfigRollout) validate(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for _, name := range []string{"maxParallel", "maxFailed"} {
+		value := rollout.MaxParallel
+		if name == "maxFailed" {
+			value = rollout.MaxFailed
+		}
+		if value == nil {
+			continue
+		}
+		scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+		if err != nil || scaled < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), value.String(),
+				"must be a non negative number or percentage"))
+		} else if name == "maxParallel" && scaled == 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), value.String(),
+				"must allow at least one node"))
+		}
+	}
+
+	return allErrs
+}
+
+var externalIDKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9][-a-zA-Z0-9_.]*$`)
+
+// external_ids keys set by the operator or by the typed OVSExternalIDs fields
+var managedExternalIDs = map[string]bool{
+	"system-id":                    true,
+	"hostname":                     true,
+	"ovn-bridge":                   true,
+	"ovn-remote":                   true,
+	"ovn-encap-type":               true,
+	"ovn-encap-ip":                 true,
+	"ovn-encap-ip-default":         true,
+	"ovn-cms-options":              true,
+	"ovn-bridge-mappings":          true,
+	"ovn-bridge-datapath-type":     true,
+	"ovn-remote-probe-interval":    true,
+	"ovn-openflow-probe-interval":  true,
+	"ovn-monitor-all":              true,
+	"ovn-ofctrl-wait-before-clear": true,
+	"ovn-encap-tos":                true,
+	"ovn-match-northd-version":     true,
+}
+
+// validateExtraExternalIDs - extra keys must not collide with the ones the operator manages
+// and keys and values must fit in the whitespace separated list passed to the config agent
+func (ids *OVSExternalIDs) validateExtraExternalIDs(fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for key, value := range ids.ExtraExternalIDs {
+		keyPath := fldPath.Key(key)
+		if managedExternalIDs[key] || strings.HasPrefix(key, "ovn-operator-") {
+			allErrs = append(allErrs, field.Forbidden(keyPath, "external_ids key is managed by the operator"))
+			continue
+		}
+		if !externalIDKeyRegexp.MatchString(key) {
+			allErrs = append(allErrs, field.Invalid(keyPath, key,
+				"must consist of alphanumeric characters, '-', '_' or '.'"))
+		}
+		if value == "" || strings.ContainsAny(value, " \t\n\"'\\") {
+			allErrs = append(allErrs, field.Invalid(keyPath, value,
+				"must not be empty nor contain whitespace, quotes or '\\'"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateHostStateDir(dir string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// empty value is replaced by the default
+	if dir == "" {
+		return allErrs
+	}
+	if !path.IsAbs(dir) || path.Clean(dir) == "/" {
+		allErrs = append(allErrs, field.Invalid(fldPath, dir, "must be an absolute path other than /"))
+	}
+
+	return allErrs
 }
 
 //+kubebuilder:webhook:path=/validate-ovn-openstack-org-v1beta1-ovncontroller,mutating=false,failurePolicy=fail,sideEffects=None,groups=ovn.openstack.org,resources=ovncontrollers,verbs=create;update,versions=v1beta1,name=vovncontroller.kb.io,admissionReviewVersions=v1
@@ -82,6 +428,13 @@ var _ webhook.Validator = &OVNController{}
 func (r *OVNController) ValidateCreate() (admission.Warnings, error) {
 	ovncontrollerlog.Info("validate create", "name", r.Name)
 
+	allErrs := r.Spec.OVNControllerSpecCore.ValidateCreate(field.NewPath("spec"))
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: "ovn.openstack.org", Kind: "OVNController"},
+			r.Name, allErrs)
+	}
+
 	return nil, nil
 }
 
@@ -89,7 +442,20 @@ func (r *OVNController) ValidateCreate() (admission.Warnings, error) {
 func (r *OVNController) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	ovncontrollerlog.Info("validate update", "name", r.Name)
 
-	return nil, nil
+	oldOVNController, ok := old.(*OVNController)
+	if !ok || oldOVNController == nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("unable to convert existing object"))
+	}
+
+	warn, allErrs := r.Spec.OVNControllerSpecCore.ValidateUpdate(
+		oldOVNController.Spec.OVNControllerSpecCore, field.NewPath("spec"))
+	if len(allErrs) != 0 {
+		return warn, apierrors.NewInvalid(
+			schema.GroupKind{Group: "ovn.openstack.org", Kind: "OVNController"},
+			r.Name, allErrs)
+	}
+
+	return warn, nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -98,3 +464,39 @@ func (r *OVNController) ValidateDelete() (admission.Warnings, error) {
 
 	return nil, nil
 }
+
+func (rollout *OVNControllerImageRollout) validate(fldPath *field.Path) field.ErrorList {
+	allErrs := metav1validation.ValidateLabels(rollout.CanaryNodeSelector, fldPath.Child("canaryNodeSelector"))
+
+	if rollout.WaveSize != nil {
+		scaled, err := intstr.GetScaledValueFromIntOrPercent(rollout.WaveSize, 100, true)
+		if err != nil || scaled <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("waveSize"), rollout.WaveSize.String(),
+				"must be a positive number or percentage"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateGracefulRestart - the wait before clear bounds must be ordered, and the wait is not also
+// set through external-ids
+func (spec *OVNControllerSpecCore) validateGracefulRestart(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	restart := spec.GracefulRestart
+	if restart == nil {
+		return allErrs
+	}
+	fldPath := basePath.Child("gracefulRestart")
+
+	if restart.MaxWaitBeforeClear < restart.MinWaitBeforeClear {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxWaitBeforeClear"), restart.MaxWaitBeforeClear,
+			fmt.Sprintf("must not be lower than %s", fldPath.Child("minWaitBeforeClear"))))
+	}
+	if spec.ExternalIDS.OvnOfctrlWaitBeforeClear != nil {
+		allErrs = append(allErrs, field.Forbidden(basePath.Child("external-ids", "ovn-ofctrl-wait-before-clear"),
+			fmt.Sprintf("can not be set together with %s", fldPath)))
+	}
+
+	return allErrs
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
@@ -124,6 +125,21 @@ type OVNDBClusterSpecCore struct {
 	TLS tls.SimpleService `json:"tls,omitempty"`
 }
 
+// OVNDBConnections - connections of a member of the DB cluster
+type OVNDBConnections struct {
+	// Sessions - JSON-RPC sessions of the clients, sessions of memory/show
+	Sessions int64 `json:"sessions"`
+
+	// Monitors - monitors the clients registered, monitors of memory/show
+	Monitors int64 `json:"monitors"`
+
+	// RaftConnections - connections to the other members, raft-connections of memory/show
+	RaftConnections int64 `json:"raftConnections"`
+
+	// Remotes - remotes the member listens on for clients, from ovsdb-server/list-remotes
+	Remotes []string `json:"remotes,omitempty"`
+}
+
 // OVNDBClusterStatus defines the observed state of OVNDBCluster
 type OVNDBClusterStatus struct {
 	// ReadyCount of OVN DBCluster instances
@@ -144,6 +160,30 @@ type OVNDBClusterStatus struct {
 	// NetworkAttachments status of the deployment pods
 	NetworkAttachments map[string][]string `json:"networkAttachments,omitempty"`
 
+	// ContainerImage - container image deployed, it lags behind spec.containerImage while
+	// the update is held until ovn-controller got updated on every chassis
+	ContainerImage string `json:"containerImage,omitempty"`
+
+	// UpgradePhase - phase of the OVN upgrade of the namespace
+	UpgradePhase string `json:"upgradePhase,omitempty"`
+
+	// TargetOVNVersion - OVN version of spec.containerImage, found by running it in a Job while
+	// its update is held until ovn-controller runs that version on every chassis
+	TargetOVNVersion *OVNImageVersion `json:"targetOVNVersion,omitempty"`
+
+	// Connections - client connections of each running member as reported by ovsdb-server,
+	// keyed by pod name. The clients of an NB cluster are NB clients, e.g. ovn-northd and
+	// neutron, the ones of an SB cluster are SB clients, e.g. ovn-northd and ovn-controller.
+	// A member which could not be queried keeps the connections of the previous check.
+	Connections map[string]OVNDBConnections `json:"connections,omitempty"`
+
+	// ClientConnections - client connections of all the members at the last check. It is
+	// kept while a member without previous connections could not be queried.
+	ClientConnections int64 `json:"clientConnections,omitempty"`
+
+	// ConnectionsCheckTime - when the connections of the members got checked last
+	ConnectionsCheckTime *metav1.Time `json:"connectionsCheckTime,omitempty"`
+
 	//ObservedGeneration - the most recent generation observed for this service. If the observed generation is less than the spec generation, then the controller has not processed the latest changes.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
@@ -198,10 +238,13 @@ func (instance OVNDBCluster) RbacResourceName() string {
 	return "ovncluster-" + instance.Name
 }
 
+// ErrDBEndpointNotReady - the OVNDBCluster did not publish the requested endpoint yet
+var ErrDBEndpointNotReady = errors.New("DBEndpoint not ready yet")
+
 // GetInternalEndpoint - return the DNS name that openshift coreDNS can resolve
 func (instance OVNDBCluster) GetInternalEndpoint() (string, error) {
 	if instance.Status.InternalDBAddress == "" {
-		return "", fmt.Errorf("internal DBEndpoint not ready yet for %s", instance.Spec.DBType)
+		return "", fmt.Errorf("internal %w for %s", ErrDBEndpointNotReady, instance.Spec.DBType)
 	}
 	return instance.Status.InternalDBAddress, nil
 }
@@ -209,7 +252,7 @@ func (instance OVNDBCluster) GetInternalEndpoint() (string, error) {
 // GetExternalEndpoint - return the DNS that openstack dnsmasq can resolve
 func (instance OVNDBCluster) GetExternalEndpoint() (string, error) {
 	if instance.Spec.NetworkAttachment != "" && instance.Status.DBAddress == "" {
-		return "", fmt.Errorf("external DBEndpoint not ready yet for %s", instance.Spec.DBType)
+		return "", fmt.Errorf("external %w for %s", ErrDBEndpointNotReady, instance.Spec.DBType)
 	}
 	return instance.Status.DBAddress, nil
 }
@@ -0,0 +1,121 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OVNDiagnosticsSpec defines the commands to run
+type OVNDiagnosticsSpec struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=ovn-northd;ovsdbserver-nb;ovsdbserver-sb;ovn-controller;ovs-vswitchd
+	// Target - service the commands run against: ovn-northd, ovsdbserver-nb and ovsdbserver-sb
+	// through ovn-appctl, ovn-controller through ovn-appctl and ovs-vswitchd through ovs-appctl
+	Target string `json:"target"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=set
+	// Commands - read only ovn-appctl/ovs-appctl commands to run, e.g. status, coverage/show or
+	// cluster/status. Commands which are not allowed for the target are refused, no command
+	// runs then. pause and resume of ovn-northd are not allowed, OVNNorthd spec.paused pauses
+	// its instances.
+	Commands []string `json:"commands"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=20
+	// Pods - names of the pods of the target to run the commands in, by default its first
+	// running pods, sorted by name, up to OVNDiagnosticsMaxPods and OVNDiagnosticsMaxCommands
+	// commands over all pods
+	Pods []string `json:"pods,omitempty"`
+}
+
+const (
+	// OVNDiagnosticsMaxPods - pods of the target the commands run in at most
+	OVNDiagnosticsMaxPods = 20
+	// OVNDiagnosticsMaxCommands - commands run at most over all pods, which bounds the time
+	// the commands of an OVNDiagnostics take
+	OVNDiagnosticsMaxCommands = 40
+)
+
+// OVNDiagnosticsStatus defines the observed state of OVNDiagnostics
+type OVNDiagnosticsStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// OutputConfigMap - ConfigMap holding the output of each command, keyed by
+	// <pod>.<command>, with / of the command replaced by -
+	OutputConfigMap string `json:"outputConfigMap,omitempty"`
+
+	// FailedCommands - <pod>.<command> keys of the commands which failed, their output
+	// holds the error
+	FailedCommands []string `json:"failedCommands,omitempty"`
+
+	// TruncatedCommands - <pod>.<command> keys of the commands whose output got truncated,
+	// to fit the size limit of the output of a command or of the output ConfigMap
+	TruncatedCommands []string `json:"truncatedCommands,omitempty"`
+
+	// SkippedPods - running pods of the target the commands did not run in, as there are
+	// more than OVNDiagnosticsMaxPods or the commands in all of them would be more than
+	// OVNDiagnosticsMaxCommands. spec.pods selects them.
+	SkippedPods int32 `json:"skippedPods,omitempty"`
+
+	// CompletionTime - when the commands of the observed generation completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this service. If the observed generation is less than the spec generation, then the controller has not processed the latest changes.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target",description="Target"
+//+kubebuilder:printcolumn:name="Output",type="string",JSONPath=".status.outputConfigMap",description="Output"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// OVNDiagnostics is the Schema for the ovndiagnostics API. The commands run once per
+// generation, changing the spec runs them again.
+type OVNDiagnostics struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OVNDiagnosticsSpec   `json:"spec,omitempty"`
+	Status OVNDiagnosticsStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OVNDiagnosticsList contains a list of OVNDiagnostics
+type OVNDiagnosticsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OVNDiagnostics `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OVNDiagnostics{}, &OVNDiagnosticsList{})
+}
+
+// IsReady - returns true if the commands of the current generation ran
+func (instance OVNDiagnostics) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
@@ -17,11 +17,15 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 )
 
 const (
@@ -33,6 +37,23 @@ const (
 	ServiceNameOVNNorthd = "ovn-northd"
 	// TODO: remove when all external consumers switch to ServiceNameOVNNorthd
 	ServiceNameOvnNorthd = "ovn-northd"
+
+	// OVNNorthdEndpointLeaderOnly - ovn-northd gets the address of each member of the DB
+	// clusters and connects to their leader
+	OVNNorthdEndpointLeaderOnly = "leaderOnly"
+	// OVNNorthdEndpointAnyMember - ovn-northd gets the address of the headless Service of
+	// the DB clusters, which resolves to any of their members, and keeps reconnecting until
+	// it reaches their leader
+	OVNNorthdEndpointAnyMember = "anyMember"
+
+	// OVNNorthdAutoscalingDisabled - ovn-northd is deployed with nThreads and resources
+	OVNNorthdAutoscalingDisabled = "disabled"
+	// OVNNorthdAutoscalingRecommend - the sizing of ovn-northd is only recorded in
+	// status.autoscaling
+	OVNNorthdAutoscalingRecommend = "recommend"
+	// OVNNorthdAutoscalingAuto - ovn-northd is deployed with the sizing recorded in
+	// status.autoscaling
+	OVNNorthdAutoscalingAuto = "auto"
 )
 
 // OVNNorthdSpec defines the desired state of OVNNorthd
@@ -77,6 +98,262 @@ type OVNNorthdSpecCore struct {
 	// +kubebuilder:default=1
 	// NThreads sets number of threads used for building logical flows
 	NThreads *int32 `json:"nThreads"`
+
+	// +kubebuilder:validation:Optional
+	// LivenessProbe - timings of the liveness probe, which fails while ovn-northd does not answer
+	// ovn-appctl, e.g. when it is stuck. Defaults to a 5s timeout, a 3s period and initial delay,
+	// and a failure threshold of 3.
+	LivenessProbe *OVNNorthdProbe `json:"livenessProbe,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ReadinessProbe - timings of the readiness probe, which fails while ovn-northd is not connected
+	// to the NB and SB DBs. Standby instances are ready. Defaults to a 10s timeout, a 5s period and
+	// initial delay, and a failure threshold of 3.
+	ReadinessProbe *OVNNorthdProbe `json:"readinessProbe,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Tuning - performance tuning of ovn-northd, applied to the options of NB_Global by a Job.
+	// Unset options are left to the OVN defaults.
+	Tuning OVNNorthdTuning `json:"tuning,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// NBGlobalOptions - options of NB_Global, e.g. mac_prefix, svc_monitor_mac or ic-route-adv,
+	// applied by the same Job as spec.tuning. Options removed from the map are removed from
+	// NB_Global, options set by other clients are left alone. Options of spec.tuning can not be
+	// set here.
+	NBGlobalOptions map[string]string `json:"nbGlobalOptions,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=leaderOnly
+	// +kubebuilder:validation:Enum=leaderOnly;anyMember
+	// EndpointMode - how the NB and SB DB endpoints passed to ovn-northd are chosen. leaderOnly
+	// passes the address of each member of the DB clusters. anyMember passes the address of
+	// their headless Service, which does not change when the DB clusters scale. ovn-northd
+	// only stays connected to the leader in both modes, so that a single instance holds the
+	// SB lock.
+	EndpointMode string `json:"endpointMode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Paused - pauses the ovn-northd instances with ovn-appctl pause, e.g. during bulk NB
+	// imports. Paused instances stop computing the SB DB and release the SB lock while their
+	// pods keep running. Clearing it resumes them.
+	Paused bool `json:"paused,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Autoscaling - sizing of nThreads and of the cpu and memory requests of ovn-northd from the
+	// SB DB cells and the memory usage of the active instance. The cells of every SB DB table
+	// are counted, as a proxy of the logical flows ovn-northd builds: it does not report how
+	// many logical flows there are.
+	Autoscaling OVNNorthdAutoscaling `json:"autoscaling,omitempty"`
+}
+
+// OVNNorthdAutoscaling - bounds of the sizing of ovn-northd and whether it gets deployed
+type OVNNorthdAutoscaling struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=disabled
+	// +kubebuilder:validation:Enum=disabled;recommend;auto
+	// Mode - disabled, recommend which only records the sizing in status.autoscaling, or auto
+	// which deploys ovn-northd with it. The sizing overrides nThreads and the cpu and memory
+	// requests of resources then, limits are kept.
+	Mode string `json:"mode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// MinNThreads - lower bound of the number of threads
+	MinNThreads *int32 `json:"minNThreads,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=4
+	// +kubebuilder:validation:Minimum=1
+	// MaxNThreads - upper bound of the number of threads
+	MaxNThreads *int32 `json:"maxNThreads,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// MinRequests - lower bounds of the cpu and memory requests
+	MinRequests corev1.ResourceList `json:"minRequests,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// MaxRequests - upper bounds of the cpu and memory requests
+	MaxRequests corev1.ResourceList `json:"maxRequests,omitempty"`
+}
+
+// OVNNorthdTuning - NB_Global options tuning ovn-northd for large deployments
+type OVNNorthdTuning struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// BackoffIntervalMs - minimum interval in ms between two recomputes of ovn-northd, which
+	// batches the NB changes of that interval (northd-backoff-interval-ms)
+	BackoffIntervalMs *int32 `json:"backoffIntervalMs,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// ProbeInterval - inactivity probe interval in ms of the connections of ovn-northd to
+	// the NB and SB DBs, 0 disables it (northd_probe_interval)
+	ProbeInterval *int32 `json:"probeInterval,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// UseParallelBuild - build the logical flows in parallel, using nThreads threads
+	// (use_parallel_build)
+	UseParallelBuild *bool `json:"useParallelBuild,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// IgnoreLSPDown - generate the flows of logical switch ports regardless of their status
+	// (ignore_lsp_down)
+	IgnoreLSPDown *bool `json:"ignoreLSPDown,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// InstallLSLBFromRouter - install the load balancers of logical routers on the logical
+	// switches attached to them (install_ls_lb_from_router)
+	InstallLSLBFromRouter *bool `json:"installLSLBFromRouter,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// UseCTInvMatch - match on the ct.inv flag in the logical flows, disable it for hardware
+	// offload which does not support it (use_ct_inv_match)
+	UseCTInvMatch *bool `json:"useCTInvMatch,omitempty"`
+}
+
+// OVNNorthdProbe - timings of a probe of ovn-northd, the unset ones get the defaults of the probe
+type OVNNorthdProbe struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// TimeoutSeconds - seconds after which the probe times out
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// PeriodSeconds - how often the probe runs
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// InitialDelaySeconds - seconds after the start of ovn-northd before the probe runs
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// FailureThreshold - consecutive failures after which the probe fails
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+var (
+	// OVNNorthdLivenessProbeDefaults - defaults of spec.livenessProbe
+	OVNNorthdLivenessProbeDefaults = OVNNorthdProbe{
+		TimeoutSeconds:      ptr.To[int32](5),
+		PeriodSeconds:       ptr.To[int32](3),
+		InitialDelaySeconds: ptr.To[int32](3),
+		FailureThreshold:    ptr.To[int32](3),
+	}
+
+	// OVNNorthdReadinessProbeDefaults - defaults of spec.readinessProbe
+	OVNNorthdReadinessProbeDefaults = OVNNorthdProbe{
+		TimeoutSeconds:      ptr.To[int32](10),
+		PeriodSeconds:       ptr.To[int32](5),
+		InitialDelaySeconds: ptr.To[int32](5),
+		FailureThreshold:    ptr.To[int32](3),
+	}
+)
+
+// Default - sets the unset timings of probe to defaults. An unset probe stays unset.
+func (probe *OVNNorthdProbe) Default(defaults OVNNorthdProbe) {
+	if probe == nil {
+		return
+	}
+	if probe.TimeoutSeconds == nil {
+		probe.TimeoutSeconds = ptr.To(*defaults.TimeoutSeconds)
+	}
+	if probe.PeriodSeconds == nil {
+		probe.PeriodSeconds = ptr.To(*defaults.PeriodSeconds)
+	}
+	if probe.InitialDelaySeconds == nil {
+		probe.InitialDelaySeconds = ptr.To(*defaults.InitialDelaySeconds)
+	}
+	if probe.FailureThreshold == nil {
+		probe.FailureThreshold = ptr.To(*defaults.FailureThreshold)
+	}
+}
+
+// GetNBGlobalOptions - NB_Global options set by tuning, keyed by OVN option name
+func (tuning OVNNorthdTuning) GetNBGlobalOptions() map[string]string {
+	options := map[string]string{}
+	if tuning.BackoffIntervalMs != nil {
+		options["northd-backoff-interval-ms"] = fmt.Sprintf("%d", *tuning.BackoffIntervalMs)
+	}
+	if tuning.ProbeInterval != nil {
+		options["northd_probe_interval"] = fmt.Sprintf("%d", *tuning.ProbeInterval)
+	}
+	if tuning.UseParallelBuild != nil {
+		options["use_parallel_build"] = fmt.Sprintf("%t", *tuning.UseParallelBuild)
+	}
+	if tuning.IgnoreLSPDown != nil {
+		options["ignore_lsp_down"] = fmt.Sprintf("%t", *tuning.IgnoreLSPDown)
+	}
+	if tuning.InstallLSLBFromRouter != nil {
+		options["install_ls_lb_from_router"] = fmt.Sprintf("%t", *tuning.InstallLSLBFromRouter)
+	}
+	if tuning.UseCTInvMatch != nil {
+		options["use_ct_inv_match"] = fmt.Sprintf("%t", *tuning.UseCTInvMatch)
+	}
+	return options
+}
+
+// GetNBGlobalOptions - NB_Global options managed by the operator, set by spec.tuning and
+// spec.nbGlobalOptions, keyed by OVN option name
+func (spec OVNNorthdSpecCore) GetNBGlobalOptions() map[string]string {
+	options := spec.Tuning.GetNBGlobalOptions()
+	// the webhook refuses options set by both
+	for key, value := range spec.NBGlobalOptions {
+		options[key] = value
+	}
+	return options
+}
+
+// Default - sets the unset bounds of autoscaling to defaults
+func (autoscaling *OVNNorthdAutoscaling) Default() {
+	if autoscaling.Mode == "" {
+		autoscaling.Mode = OVNNorthdAutoscalingDisabled
+	}
+	if autoscaling.MinNThreads == nil {
+		autoscaling.MinNThreads = ptr.To[int32](1)
+	}
+	if autoscaling.MaxNThreads == nil {
+		autoscaling.MaxNThreads = ptr.To[int32](4)
+	}
+}
+
+// OVNNorthdAutoscalingStatus - sizing of ovn-northd computed by spec.autoscaling, with the
+// inputs it got computed from
+type OVNNorthdAutoscalingStatus struct {
+	// SBCells - cells of every table of the SB DB held by the active ovn-northd instance, as
+	// reported by ovn-appctl memory/show. It is not a count of the logical flows, their rows
+	// usually make most of the cells.
+	SBCells int64 `json:"sbCells"`
+
+	// MemoryUsage - resident memory of the active ovn-northd instance
+	MemoryUsage resource.Quantity `json:"memoryUsage"`
+
+	// Instance - ovn-northd pod the inputs got collected through
+	Instance string `json:"instance"`
+
+	// CollectionTime - when the inputs got collected
+	CollectionTime metav1.Time `json:"collectionTime"`
+
+	// NThreads - number of threads for the SB DB cells
+	NThreads int32 `json:"nThreads"`
+
+	// ScaleDownTime - collection time of the first inputs asking for fewer threads than
+	// nThreads since. The threads get removed once the inputs kept asking for it for
+	// the scale down stabilization window.
+	ScaleDownTime *metav1.Time `json:"scaleDownTime,omitempty"`
+
+	// Requests - cpu and memory requests for the SB DB cells and the memory usage
+	Requests corev1.ResourceList `json:"requests"`
+
+	// Applied - whether ovn-northd is deployed with this sizing, only in the auto mode
+	Applied bool `json:"applied"`
+
+	// Reason - how the sizing got computed from the inputs
+	Reason string `json:"reason"`
 }
 
 // OVNNorthdStatus defines the observed state of OVNNorthd
@@ -87,6 +364,51 @@ type OVNNorthdStatus struct {
 	// Conditions
 	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
 
+	// ContainerImage - container image deployed, it lags behind spec.containerImage while
+	// the update is held until ovn-controller got updated on every chassis
+	ContainerImage string `json:"containerImage,omitempty"`
+
+	// UpgradePhase - phase of the OVN upgrade of the namespace
+	UpgradePhase string `json:"upgradePhase,omitempty"`
+
+	// TargetOVNVersion - OVN version of spec.containerImage, found by running it in a Job while
+	// its update is held until ovn-controller runs that version on every chassis
+	TargetOVNVersion *OVNImageVersion `json:"targetOVNVersion,omitempty"`
+
+	// ActiveInstance - ovn-northd pod holding the SB lock, the other instances are standby
+	ActiveInstance string `json:"activeInstance,omitempty"`
+
+	// Instances - state of each running ovn-northd pod as reported by ovn-appctl status,
+	// active, standby or paused, keyed by pod name. Pods which could not be queried are
+	// reported as unknown.
+	Instances map[string]string `json:"instances,omitempty"`
+
+	// InstancesCheckTime - time the ovn-northd instances were last queried
+	InstancesCheckTime *metav1.Time `json:"instancesCheckTime,omitempty"`
+
+	// NBConnection - NB DB connection string passed to ovn-northd
+	NBConnection string `json:"nbConnection,omitempty"`
+
+	// SBConnection - SB DB connection string passed to ovn-northd
+	SBConnection string `json:"sbConnection,omitempty"`
+
+	// NBGlobalOptions - NB_Global options managed by the operator with the value read back from
+	// NB_Global at the last check, or the one applied by the NB_Global options Job until then
+	NBGlobalOptions map[string]string `json:"nbGlobalOptions,omitempty"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// NBGlobalOptionsDrift - NB_Global options managed by the operator found with another
+	// value at the last check, as option=value found. They get applied again.
+	NBGlobalOptionsDrift []string `json:"nbGlobalOptionsDrift,omitempty"`
+
+	// NBGlobalOptionsCheckTime - time NB_Global was last checked for drift
+	NBGlobalOptionsCheckTime *metav1.Time `json:"nbGlobalOptionsCheckTime,omitempty"`
+
+	// Autoscaling - sizing of ovn-northd computed by spec.autoscaling, unset while it is disabled
+	Autoscaling *OVNNorthdAutoscalingStatus `json:"autoscaling,omitempty"`
+
 	//ObservedGeneration - the most recent generation observed for this service. If the observed generation is less than the spec generation, then the controller has not processed the latest changes.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
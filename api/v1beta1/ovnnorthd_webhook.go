@@ -23,7 +23,14 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -74,7 +81,48 @@ func (spec *OVNNorthdSpec) Default() {
 
 // Default - set defaults for this OVNNorthd core spec (this version is called by OpenStackControlplane webhooks)
 func (spec *OVNNorthdSpecCore) Default() {
-	// nothing here yet
+	if spec.EndpointMode == "" {
+		spec.EndpointMode = OVNNorthdEndpointLeaderOnly
+	}
+	spec.LivenessProbe.Default(OVNNorthdLivenessProbeDefaults)
+	spec.ReadinessProbe.Default(OVNNorthdReadinessProbeDefaults)
+	spec.Autoscaling.Default()
+}
+
+// validateAutoscaling - the lower bounds must not exceed the upper ones, only cpu and memory
+// requests are sized
+func (spec *OVNNorthdSpecCore) validateAutoscaling(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	fldPath := basePath.Child("autoscaling")
+	autoscaling := spec.Autoscaling
+	if autoscaling.MinNThreads != nil && autoscaling.MaxNThreads != nil &&
+		*autoscaling.MinNThreads > *autoscaling.MaxNThreads {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minNThreads"), *autoscaling.MinNThreads,
+			"must not be greater than maxNThreads"))
+	}
+	for _, bounds := range []struct {
+		name      string
+		resources corev1.ResourceList
+	}{
+		{"minRequests", autoscaling.MinRequests},
+		{"maxRequests", autoscaling.MaxRequests},
+	} {
+		for name := range bounds.resources {
+			if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+				allErrs = append(allErrs, field.NotSupported(fldPath.Child(bounds.name).Key(string(name)), name,
+					[]string{string(corev1.ResourceCPU), string(corev1.ResourceMemory)}))
+			}
+		}
+	}
+	for name, minRequest := range autoscaling.MinRequests {
+		if maxRequest, ok := autoscaling.MaxRequests[name]; ok && minRequest.Cmp(maxRequest) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("minRequests").Key(string(name)), minRequest.String(),
+				"must not be greater than maxRequests"))
+		}
+	}
+
+	return allErrs
 }
 
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
@@ -82,11 +130,58 @@ func (spec *OVNNorthdSpecCore) Default() {
 
 var _ webhook.Validator = &OVNNorthd{}
 
+// ValidateCreate - validate the OVNNorthd core spec (this version is called by OpenStackControlplane webhooks)
+func (spec *OVNNorthdSpecCore) ValidateCreate(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	allErrs = append(allErrs, spec.validateNBGlobalOptions(basePath)...)
+	allErrs = append(allErrs, spec.validateAutoscaling(basePath)...)
+
+	return allErrs
+}
+
+// ValidateUpdate - validate the OVNNorthd core spec update (this version is called by OpenStackControlplane webhooks)
+func (spec *OVNNorthdSpecCore) ValidateUpdate(_ OVNNorthdSpecCore, basePath *field.Path) field.ErrorList {
+	return spec.ValidateCreate(basePath)
+}
+
+// validateNBGlobalOptions - options must not be set by spec.tuning too, and keys and values
+// must fit in the whitespace separated list passed to the NB_Global options Job
+func (spec *OVNNorthdSpecCore) validateNBGlobalOptions(basePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	fldPath := basePath.Child("nbGlobalOptions")
+	tuningOptions := spec.Tuning.GetNBGlobalOptions()
+	for key, value := range spec.NBGlobalOptions {
+		keyPath := fldPath.Key(key)
+		if _, ok := tuningOptions[key]; ok {
+			allErrs = append(allErrs, field.Forbidden(keyPath, "option is set by spec.tuning"))
+			continue
+		}
+		if !externalIDKeyRegexp.MatchString(key) {
+			allErrs = append(allErrs, field.Invalid(keyPath, key,
+				"must consist of alphanumeric characters, '-', '_' or '.'"))
+		}
+		if value == "" || strings.ContainsAny(value, " \t\n\"'\\") {
+			allErrs = append(allErrs, field.Invalid(keyPath, value,
+				"must not be empty nor contain whitespace, quotes or '\\'"))
+		}
+	}
+
+	return allErrs
+}
+
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *OVNNorthd) ValidateCreate() (admission.Warnings, error) {
 	ovnnorthdlog.Info("validate create", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
+	allErrs := r.Spec.OVNNorthdSpecCore.ValidateCreate(field.NewPath("spec"))
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: "ovn.openstack.org", Kind: "OVNNorthd"},
+			r.Name, allErrs)
+	}
+
 	return nil, nil
 }
 
@@ -94,7 +189,18 @@ func (r *OVNNorthd) ValidateCreate() (admission.Warnings, error) {
 func (r *OVNNorthd) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	ovnnorthdlog.Info("validate update", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object update.
+	oldOVNNorthd, ok := old.(*OVNNorthd)
+	if !ok || oldOVNNorthd == nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("unable to convert existing object"))
+	}
+
+	allErrs := r.Spec.OVNNorthdSpecCore.ValidateUpdate(oldOVNNorthd.Spec.OVNNorthdSpecCore, field.NewPath("spec"))
+	if len(allErrs) != 0 {
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: "ovn.openstack.org", Kind: "OVNNorthd"},
+			r.Name, allErrs)
+	}
+
 	return nil, nil
 }
 
@@ -0,0 +1,166 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OVN recommends to upgrade ovn-controller on the chassis before ovn-northd and the DBs. The
+// OVNNorthd and OVNDBCluster reconcilers find the OVN version of their new container image with
+// a Job, and hold the update until every chassis of the namespace reports that OVN version or a
+// later one in OVNController Status.ChassisOVNVersion.
+const (
+	// UpgradePhaseChassis - ovn-controller is being updated on the chassis
+	UpgradePhaseChassis = "ChassisUpgrading"
+	// UpgradePhaseCentral - ovn-northd or the DBs are being updated
+	UpgradePhaseCentral = "CentralUpgrading"
+	// UpgradePhaseDone - all the components run their spec container image
+	UpgradePhaseDone = "Done"
+)
+
+// OVNImageVersion - OVN version of a container image
+type OVNImageVersion struct {
+	// Image - container image
+	Image string `json:"image"`
+
+	// Version - OVN version of the image, as printed by ovn-appctl --version
+	Version string `json:"version"`
+}
+
+// IsOVNVersionAtLeast - whether the OVN version version is target or a later one
+func IsOVNVersionAtLeast(version string, target string) bool {
+	v, err := utilversion.ParseGeneric(version)
+	if err != nil {
+		return false
+	}
+	t, err := utilversion.ParseGeneric(target)
+	if err != nil {
+		return false
+	}
+	return v.AtLeast(t)
+}
+
+// IsChassisUpgraded - whether ovn-controller runs OVN targetVersion or a later one on all the
+// chassis of every OVNController of the namespace. OVNControllers without any chassis only need
+// to have rolled their image out. OVNControllers setting ovn-match-northd-version do not need to
+// be updated first, ovn-controller waits for ovn-northd to run the same version.
+func IsChassisUpgraded(
+	ctx context.Context,
+	h *helper.Helper,
+	namespace string,
+	targetVersion string,
+) (bool, error) {
+	ovnControllerList := &OVNControllerList{}
+	err := h.GetClient().List(ctx, ovnControllerList, client.InNamespace(namespace))
+	if err != nil {
+		return false, err
+	}
+	for _, ovnController := range ovnControllerList.Items {
+		if ptr.Deref(ovnController.Spec.ExternalIDS.OvnMatchNorthdVersion, false) {
+			continue
+		}
+		if len(ovnController.Status.Nodes) == 0 {
+			if ovnController.Status.ChassisOvnImage != ovnController.Spec.OvnContainerImage {
+				return false, nil
+			}
+			continue
+		}
+		if !IsOVNVersionAtLeast(ovnController.Status.ChassisOVNVersion, targetVersion) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// GetCentralContainerImage - returns the container image ovn-northd or the DBs have to deploy,
+// the spec one unless it updates deployedImage before ovn-controller runs targetVersion, the
+// OVN version of the spec image, on every chassis. An unknown targetVersion holds the update
+// too. held reports if the update is held.
+func GetCentralContainerImage(
+	ctx context.Context,
+	h *helper.Helper,
+	namespace string,
+	specImage string,
+	deployedImage string,
+	targetVersion string,
+) (image string, held bool, err error) {
+	if deployedImage == "" || deployedImage == specImage {
+		return specImage, false, nil
+	}
+	if targetVersion == "" {
+		return deployedImage, true, nil
+	}
+	upgraded, err := IsChassisUpgraded(ctx, h, namespace, targetVersion)
+	if err != nil {
+		return "", false, err
+	}
+	if !upgraded {
+		return deployedImage, true, nil
+	}
+	return specImage, false, nil
+}
+
+// GetUpgradePhase - returns the phase of the OVN upgrade of the namespace. OVNControllers
+// setting ovn-match-northd-version are not waited for, as in IsChassisUpgraded.
+func GetUpgradePhase(
+	ctx context.Context,
+	h *helper.Helper,
+	namespace string,
+) (string, error) {
+	ovnControllerList := &OVNControllerList{}
+	err := h.GetClient().List(ctx, ovnControllerList, client.InNamespace(namespace))
+	if err != nil {
+		return "", err
+	}
+	for _, ovnController := range ovnControllerList.Items {
+		if ptr.Deref(ovnController.Spec.ExternalIDS.OvnMatchNorthdVersion, false) {
+			continue
+		}
+		if ovnController.Status.ChassisOvnImage != ovnController.Spec.OvnContainerImage {
+			return UpgradePhaseChassis, nil
+		}
+	}
+
+	northdList := &OVNNorthdList{}
+	err = h.GetClient().List(ctx, northdList, client.InNamespace(namespace))
+	if err != nil {
+		return "", err
+	}
+	for _, northd := range northdList.Items {
+		if northd.Status.ContainerImage != northd.Spec.ContainerImage {
+			return UpgradePhaseCentral, nil
+		}
+	}
+
+	dbClusterList, err := getDBClusters(ctx, h, namespace, map[string]string{})
+	if err != nil {
+		return "", err
+	}
+	for _, dbCluster := range dbClusterList.Items {
+		if dbCluster.Status.ContainerImage != dbCluster.Spec.ContainerImage {
+			return UpgradePhaseCentral, nil
+		}
+	}
+
+	return UpgradePhaseDone, nil
+}
@@ -23,9 +23,32 @@ package v1beta1
 
 import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NicMappingConfig) DeepCopyInto(out *NicMappingConfig) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NicMappingConfig.
+func (in *NicMappingConfig) DeepCopy() *NicMappingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NicMappingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNController) DeepCopyInto(out *OVNController) {
 	*out = *in
@@ -53,6 +76,60 @@ func (in *OVNController) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerConfigRollout) DeepCopyInto(out *OVNControllerConfigRollout) {
+	*out = *in
+	if in.MaxParallel != nil {
+		in, out := &in.MaxParallel, &out.MaxParallel
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxFailed != nil {
+		in, out := &in.MaxFailed, &out.MaxFailed
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerConfigRollout.
+func (in *OVNControllerConfigRollout) DeepCopy() *OVNControllerConfigRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerConfigRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerConfigRolloutStatus) DeepCopyInto(out *OVNControllerConfigRolloutStatus) {
+	*out = *in
+	if in.PendingNodes != nil {
+		in, out := &in.PendingNodes, &out.PendingNodes
+		*out = make(map[string]v1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.FailedNodes != nil {
+		in, out := &in.FailedNodes, &out.FailedNodes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerConfigRolloutStatus.
+func (in *OVNControllerConfigRolloutStatus) DeepCopy() *OVNControllerConfigRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerConfigRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNControllerDefaults) DeepCopyInto(out *OVNControllerDefaults) {
 	*out = *in
@@ -68,6 +145,100 @@ func (in *OVNControllerDefaults) DeepCopy() *OVNControllerDefaults {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerFlowRestoreStatus) DeepCopyInto(out *OVNControllerFlowRestoreStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerFlowRestoreStatus.
+func (in *OVNControllerFlowRestoreStatus) DeepCopy() *OVNControllerFlowRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerFlowRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerGracefulRestart) DeepCopyInto(out *OVNControllerGracefulRestart) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerGracefulRestart.
+func (in *OVNControllerGracefulRestart) DeepCopy() *OVNControllerGracefulRestart {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerGracefulRestart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerImageRollout) DeepCopyInto(out *OVNControllerImageRollout) {
+	*out = *in
+	if in.CanaryNodeSelector != nil {
+		in, out := &in.CanaryNodeSelector, &out.CanaryNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.WaveSize != nil {
+		in, out := &in.WaveSize, &out.WaveSize
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MinHealthySeconds != nil {
+		in, out := &in.MinHealthySeconds, &out.MinHealthySeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerImageRollout.
+func (in *OVNControllerImageRollout) DeepCopy() *OVNControllerImageRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerImageRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerImageRolloutStatus) DeepCopyInto(out *OVNControllerImageRolloutStatus) {
+	*out = *in
+	if in.WaveNodes != nil {
+		in, out := &in.WaveNodes, &out.WaveNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WaveStartTime != nil {
+		in, out := &in.WaveStartTime, &out.WaveStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.UnhealthyNodes != nil {
+		in, out := &in.UnhealthyNodes, &out.UnhealthyNodes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerImageRolloutStatus.
+func (in *OVNControllerImageRolloutStatus) DeepCopy() *OVNControllerImageRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerImageRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNControllerList) DeepCopyInto(out *OVNControllerList) {
 	*out = *in
@@ -100,6 +271,51 @@ func (in *OVNControllerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerNodeStatus) DeepCopyInto(out *OVNControllerNodeStatus) {
+	*out = *in
+	if in.BridgeDrift != nil {
+		in, out := &in.BridgeDrift, &out.BridgeDrift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OffloadedFlows != nil {
+		in, out := &in.OffloadedFlows, &out.OffloadedFlows
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FlowRestore != nil {
+		in, out := &in.FlowRestore, &out.FlowRestore
+		*out = new(OVNControllerFlowRestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecomputeTime != nil {
+		in, out := &in.RecomputeTime, &out.RecomputeTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.WaitBeforeClear != nil {
+		in, out := &in.WaitBeforeClear, &out.WaitBeforeClear
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RestartDowntime != nil {
+		in, out := &in.RestartDowntime, &out.RestartDowntime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerNodeStatus.
+func (in *OVNControllerNodeStatus) DeepCopy() *OVNControllerNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNControllerSpec) DeepCopyInto(out *OVNControllerSpec) {
 	*out = *in
@@ -127,6 +343,20 @@ func (in *OVNControllerSpecCore) DeepCopyInto(out *OVNControllerSpecCore) {
 			(*out)[key] = val
 		}
 	}
+	if in.NicMappingsConfig != nil {
+		in, out := &in.NicMappingsConfig, &out.NicMappingsConfig
+		*out = make(map[string]NicMappingConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Bridges != nil {
+		in, out := &in.Bridges, &out.Bridges
+		*out = make([]OVSBridge, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
@@ -135,7 +365,33 @@ func (in *OVNControllerSpecCore) DeepCopyInto(out *OVNControllerSpecCore) {
 			(*out)[key] = val
 		}
 	}
+	if in.EncapNetworkAttachments != nil {
+		in, out := &in.EncapNetworkAttachments, &out.EncapNetworkAttachments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.TLS.DeepCopyInto(&out.TLS)
+	if in.DPDK != nil {
+		in, out := &in.DPDK, &out.DPDK
+		*out = new(OVSDPDKSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HardwareOffload != nil {
+		in, out := &in.HardwareOffload, &out.HardwareOffload
+		*out = new(OVSHardwareOffloadSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ConfigRollout.DeepCopyInto(&out.ConfigRollout)
+	if in.ImageRollout != nil {
+		in, out := &in.ImageRollout, &out.ImageRollout
+		*out = new(OVNControllerImageRollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GracefulRestart != nil {
+		in, out := &in.GracefulRestart, &out.GracefulRestart
+		*out = new(OVNControllerGracefulRestart)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerSpecCore.
@@ -180,6 +436,28 @@ func (in *OVNControllerStatus) DeepCopyInto(out *OVNControllerStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.UnmanagedNetworkAttachments != nil {
+		in, out := &in.UnmanagedNetworkAttachments, &out.UnmanagedNetworkAttachments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make(map[string]OVNControllerNodeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ConfigRollout != nil {
+		in, out := &in.ConfigRollout, &out.ConfigRollout
+		*out = new(OVNControllerConfigRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRollout != nil {
+		in, out := &in.ImageRollout, &out.ImageRollout
+		*out = new(OVNControllerImageRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerStatus.
@@ -343,6 +621,22 @@ func (in *OVNDBClusterStatus) DeepCopyInto(out *OVNDBClusterStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.TargetOVNVersion != nil {
+		in, out := &in.TargetOVNVersion, &out.TargetOVNVersion
+		*out = new(OVNImageVersion)
+		**out = **in
+	}
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = make(map[string]OVNDBConnections, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ConnectionsCheckTime != nil {
+		in, out := &in.ConnectionsCheckTime, &out.ConnectionsCheckTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNDBClusterStatus.
@@ -355,6 +649,161 @@ func (in *OVNDBClusterStatus) DeepCopy() *OVNDBClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNDBConnections) DeepCopyInto(out *OVNDBConnections) {
+	*out = *in
+	if in.Remotes != nil {
+		in, out := &in.Remotes, &out.Remotes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNDBConnections.
+func (in *OVNDBConnections) DeepCopy() *OVNDBConnections {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNDBConnections)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNDiagnostics) DeepCopyInto(out *OVNDiagnostics) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNDiagnostics.
+func (in *OVNDiagnostics) DeepCopy() *OVNDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OVNDiagnostics) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNDiagnosticsList) DeepCopyInto(out *OVNDiagnosticsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OVNDiagnostics, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNDiagnosticsList.
+func (in *OVNDiagnosticsList) DeepCopy() *OVNDiagnosticsList {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNDiagnosticsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OVNDiagnosticsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNDiagnosticsSpec) DeepCopyInto(out *OVNDiagnosticsSpec) {
+	*out = *in
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNDiagnosticsSpec.
+func (in *OVNDiagnosticsSpec) DeepCopy() *OVNDiagnosticsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNDiagnosticsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNDiagnosticsStatus) DeepCopyInto(out *OVNDiagnosticsStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FailedCommands != nil {
+		in, out := &in.FailedCommands, &out.FailedCommands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TruncatedCommands != nil {
+		in, out := &in.TruncatedCommands, &out.TruncatedCommands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNDiagnosticsStatus.
+func (in *OVNDiagnosticsStatus) DeepCopy() *OVNDiagnosticsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNDiagnosticsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNImageVersion) DeepCopyInto(out *OVNImageVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNImageVersion.
+func (in *OVNImageVersion) DeepCopy() *OVNImageVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNImageVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNNorthd) DeepCopyInto(out *OVNNorthd) {
 	*out = *in
@@ -382,6 +831,73 @@ func (in *OVNNorthd) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNNorthdAutoscaling) DeepCopyInto(out *OVNNorthdAutoscaling) {
+	*out = *in
+	if in.MinNThreads != nil {
+		in, out := &in.MinNThreads, &out.MinNThreads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxNThreads != nil {
+		in, out := &in.MaxNThreads, &out.MaxNThreads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinRequests != nil {
+		in, out := &in.MinRequests, &out.MinRequests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxRequests != nil {
+		in, out := &in.MaxRequests, &out.MaxRequests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNNorthdAutoscaling.
+func (in *OVNNorthdAutoscaling) DeepCopy() *OVNNorthdAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNNorthdAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNNorthdAutoscalingStatus) DeepCopyInto(out *OVNNorthdAutoscalingStatus) {
+	*out = *in
+	out.MemoryUsage = in.MemoryUsage.DeepCopy()
+	in.CollectionTime.DeepCopyInto(&out.CollectionTime)
+	if in.ScaleDownTime != nil {
+		in, out := &in.ScaleDownTime, &out.ScaleDownTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNNorthdAutoscalingStatus.
+func (in *OVNNorthdAutoscalingStatus) DeepCopy() *OVNNorthdAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNNorthdAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNNorthdDefaults) DeepCopyInto(out *OVNNorthdDefaults) {
 	*out = *in
@@ -429,6 +945,41 @@ func (in *OVNNorthdList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNNorthdProbe) DeepCopyInto(out *OVNNorthdProbe) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNNorthdProbe.
+func (in *OVNNorthdProbe) DeepCopy() *OVNNorthdProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNNorthdProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNNorthdSpec) DeepCopyInto(out *OVNNorthdSpec) {
 	*out = *in
@@ -467,6 +1018,25 @@ func (in *OVNNorthdSpecCore) DeepCopyInto(out *OVNNorthdSpecCore) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(OVNNorthdProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(OVNNorthdProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Tuning.DeepCopyInto(&out.Tuning)
+	if in.NBGlobalOptions != nil {
+		in, out := &in.NBGlobalOptions, &out.NBGlobalOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Autoscaling.DeepCopyInto(&out.Autoscaling)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNNorthdSpecCore.
@@ -489,6 +1059,50 @@ func (in *OVNNorthdStatus) DeepCopyInto(out *OVNNorthdStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TargetOVNVersion != nil {
+		in, out := &in.TargetOVNVersion, &out.TargetOVNVersion
+		*out = new(OVNImageVersion)
+		**out = **in
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InstancesCheckTime != nil {
+		in, out := &in.InstancesCheckTime, &out.InstancesCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NBGlobalOptions != nil {
+		in, out := &in.NBGlobalOptions, &out.NBGlobalOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NBGlobalOptionsDrift != nil {
+		in, out := &in.NBGlobalOptionsDrift, &out.NBGlobalOptionsDrift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NBGlobalOptionsCheckTime != nil {
+		in, out := &in.NBGlobalOptionsCheckTime, &out.NBGlobalOptionsCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(OVNNorthdAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNNorthdStatus.
@@ -501,6 +1115,124 @@ func (in *OVNNorthdStatus) DeepCopy() *OVNNorthdStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNNorthdTuning) DeepCopyInto(out *OVNNorthdTuning) {
+	*out = *in
+	if in.BackoffIntervalMs != nil {
+		in, out := &in.BackoffIntervalMs, &out.BackoffIntervalMs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ProbeInterval != nil {
+		in, out := &in.ProbeInterval, &out.ProbeInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UseParallelBuild != nil {
+		in, out := &in.UseParallelBuild, &out.UseParallelBuild
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IgnoreLSPDown != nil {
+		in, out := &in.IgnoreLSPDown, &out.IgnoreLSPDown
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InstallLSLBFromRouter != nil {
+		in, out := &in.InstallLSLBFromRouter, &out.InstallLSLBFromRouter
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseCTInvMatch != nil {
+		in, out := &in.UseCTInvMatch, &out.UseCTInvMatch
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNNorthdTuning.
+func (in *OVNNorthdTuning) DeepCopy() *OVNNorthdTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNNorthdTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridge) DeepCopyInto(out *OVSBridge) {
+	*out = *in
+	if in.PhysicalNetworks != nil {
+		in, out := &in.PhysicalNetworks, &out.PhysicalNetworks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Uplink != nil {
+		in, out := &in.Uplink, &out.Uplink
+		*out = new(OVSBridgeUplink)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridge.
+func (in *OVSBridge) DeepCopy() *OVSBridge {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridgeUplink) DeepCopyInto(out *OVSBridgeUplink) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Trunks != nil {
+		in, out := &in.Trunks, &out.Trunks
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridgeUplink.
+func (in *OVSBridgeUplink) DeepCopy() *OVSBridgeUplink {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridgeUplink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSDPDKSpec) DeepCopyInto(out *OVSDPDKSpec) {
+	*out = *in
+	out.Hugepages = in.Hugepages.DeepCopy()
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSDPDKSpec.
+func (in *OVSDPDKSpec) DeepCopy() *OVSDPDKSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSDPDKSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVSExternalIDs) DeepCopyInto(out *OVSExternalIDs) {
 	*out = *in
@@ -514,6 +1246,38 @@ func (in *OVSExternalIDs) DeepCopyInto(out *OVSExternalIDs) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.OvnRemoteProbeInterval != nil {
+		in, out := &in.OvnRemoteProbeInterval, &out.OvnRemoteProbeInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OvnOpenflowProbeInterval != nil {
+		in, out := &in.OvnOpenflowProbeInterval, &out.OvnOpenflowProbeInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OvnMonitorAll != nil {
+		in, out := &in.OvnMonitorAll, &out.OvnMonitorAll
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OvnOfctrlWaitBeforeClear != nil {
+		in, out := &in.OvnOfctrlWaitBeforeClear, &out.OvnOfctrlWaitBeforeClear
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OvnMatchNorthdVersion != nil {
+		in, out := &in.OvnMatchNorthdVersion, &out.OvnMatchNorthdVersion
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExtraExternalIDs != nil {
+		in, out := &in.ExtraExternalIDs, &out.ExtraExternalIDs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSExternalIDs.
@@ -525,3 +1289,23 @@ func (in *OVSExternalIDs) DeepCopy() *OVSExternalIDs {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSHardwareOffloadSpec) DeepCopyInto(out *OVSHardwareOffloadSpec) {
+	*out = *in
+	if in.PhysicalFunctions != nil {
+		in, out := &in.PhysicalFunctions, &out.PhysicalFunctions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSHardwareOffloadSpec.
+func (in *OVSHardwareOffloadSpec) DeepCopy() *OVSHardwareOffloadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSHardwareOffloadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
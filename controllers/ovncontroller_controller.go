@@ -20,19 +20,23 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -44,7 +48,6 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/daemonset"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
-	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
 	nad "github.com/openstack-k8s-operators/lib-common/modules/common/networkattachment"
 	common_rbac "github.com/openstack-k8s-operators/lib-common/modules/common/rbac"
@@ -53,7 +56,6 @@ import (
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovncontroller"
 	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 )
@@ -66,8 +68,9 @@ func (r *OVNControllerReconciler) GetLogger(ctx context.Context) logr.Logger {
 // OVNControllerReconciler reconciles a OVNController object
 type OVNControllerReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient  kubernetes.Interface
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // GetClient -
@@ -80,10 +83,13 @@ func (r *OVNControllerReconciler) GetClient() client.Client {
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovncontrollers/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
 //+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;patch;update;delete;
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndbclusters,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovnnorthds,verbs=get;list;watch;
 //+kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=create;delete;get;list;patch;update;watch
 
 // service account, role, rolebinding
@@ -170,6 +176,9 @@ func (r *OVNControllerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		condition.UnknownCondition(condition.RoleReadyCondition, condition.InitReason, condition.RoleReadyInitMessage),
 		condition.UnknownCondition(condition.RoleBindingReadyCondition, condition.InitReason, condition.RoleBindingReadyInitMessage),
 		condition.UnknownCondition(condition.TLSInputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNControllerHostStateDirReadyCondition, condition.InitReason, ovnv1.OVNControllerHostStateDirReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNControllerConfigRolloutReadyCondition, condition.InitReason, ovnv1.OVNControllerConfigRolloutReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNControllerImageRolloutReadyCondition, condition.InitReason, ovnv1.OVNControllerImageRolloutReadyInitMessage),
 	)
 
 	instance.Status.Conditions.Init(&cl)
@@ -220,21 +229,78 @@ func (r *OVNControllerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&ovnv1.OVNController{}).
 		Owns(&corev1.ConfigMap{}).
-		Owns(&batchv1.Job{}).
 		Owns(&netattdefv1.NetworkAttachmentDefinition{}).
 		Owns(&appsv1.DaemonSet{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
 		Watches(&ovnv1.OVNDBCluster{}, handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient()))).
+		Watches(&ovnv1.OVNNorthd{}, handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient()))).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		// the per node state is reported as annotations on the ovs and ovn-controller
+		// pods and image rollouts wait for the replaced pods to become ready
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForPod),
+			builder.WithPredicates(
+				ovsPodPredicate,
+				predicate.Or(predicate.AnnotationChangedPredicate{}, podReadyChangedPredicate),
+			),
+		).
 		Complete(r)
 }
 
+// ovsPodPredicate - passes the events of the ovs and ovn-controller pods
+var ovsPodPredicate = predicate.NewPredicateFuncs(func(o client.Object) bool {
+	service := o.GetLabels()[common.AppSelector]
+	return service == ovnv1.ServiceNameOVS || service == ovnv1.ServiceNameOVNController
+})
+
+// podReadyChangedPredicate - passes the updates changing the Ready condition of a pod
+var podReadyChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return isPodReady(e.ObjectOld) != isPodReady(e.ObjectNew)
+	},
+}
+
+func isPodReady(obj client.Object) bool {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (r *OVNControllerReconciler) findObjectsForPod(ctx context.Context, pod client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	crList := &ovnv1.OVNControllerList{}
+	if err := r.Client.List(ctx, crList, client.InNamespace(pod.GetNamespace())); err != nil {
+		return requests
+	}
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
+
 func (r *OVNControllerReconciler) findObjectsForSrc(ctx context.Context, src client.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 
@@ -453,9 +519,29 @@ func (r *OVNControllerReconciler) reconcileNormal(ctx context.Context, instance
 	}
 
 	// Create or Update additional Physical Network Attachments
-	networkAttachments, err := ovncontroller.CreateOrUpdateAdditionalNetworks(ctx, helper, instance, ovsServiceLabels)
+	additionalNetworks, err := ovncontroller.CreateOrUpdateAdditionalNetworks(ctx, helper, instance, ovsServiceLabels)
 	if err != nil {
 		Log.Info(fmt.Sprintf("Failed to create additional networks: %s", err))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.NetworkAttachmentsReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.NetworkAttachmentsReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	networkAttachments := additionalNetworks.Names
+	instance.Status.UnmanagedNetworkAttachments = additionalNetworks.Unmanaged
+
+	// Physical networks removed from the spec must not stay attached to the OVS pods
+	err = ovncontroller.DeleteOrphanedAdditionalNetworks(ctx, helper, instance, ovsServiceLabels, networkAttachments)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.NetworkAttachmentsReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.NetworkAttachmentsReadyErrorMessage,
+			err.Error()))
 		return ctrl.Result{}, err
 	}
 
@@ -465,6 +551,8 @@ func (r *OVNControllerReconciler) reconcileNormal(ctx context.Context, instance
 		networkAttachments = append(networkAttachments, instance.Spec.NetworkAttachment)
 		networkAttachmentsNoPhysNet = append(networkAttachmentsNoPhysNet, instance.Spec.NetworkAttachment)
 	}
+	networkAttachments = append(networkAttachments, instance.Spec.EncapNetworkAttachments...)
+	networkAttachmentsNoPhysNet = append(networkAttachmentsNoPhysNet, instance.Spec.EncapNetworkAttachments...)
 	sort.Strings(networkAttachments)
 
 	for _, netAtt := range networkAttachments {
@@ -496,6 +584,10 @@ func (r *OVNControllerReconciler) reconcileNormal(ctx context.Context, instance
 			networkAttachments, err)
 	}
 
+	// The OVS/OVN state of the nodes lives below the host state directory,
+	// resolve the one the DaemonSets have to mount
+	r.reconcileHostStateDir(ctx, instance)
+
 	// Handle service init
 	ctrlResult, err := r.reconcileInit(ctx)
 	if err != nil {
@@ -521,8 +613,23 @@ func (r *OVNControllerReconciler) reconcileNormal(ctx context.Context, instance
 	}
 
 	// Define a new DaemonSet object for OVNController
+	ovnDaemonSet := ovncontroller.CreateOVNDaemonSet(instance, inputHash, ovnServiceLabels)
+	// the pods get labelled with their template hash only for image rollouts, which
+	// find the outdated pods with it
+	templateHashes := map[string]string{}
+	if instance.Spec.ImageRollout != nil {
+		templateHashes[ovnv1.ServiceNameOVNController], err = ovncontroller.SetTemplateHash(ovnDaemonSet)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	// with spec.imageRollout the pods get replaced by the operator, see RolloutImages
+	err = ovncontroller.EnsureUpdateStrategy(ctx, r.Client, instance, ovnDaemonSet.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	dset := daemonset.NewDaemonSet(
-		ovncontroller.CreateOVNDaemonSet(instance, inputHash, ovnServiceLabels),
+		ovnDaemonSet,
 		time.Duration(5)*time.Second,
 	)
 
@@ -548,8 +655,19 @@ func (r *OVNControllerReconciler) reconcileNormal(ctx context.Context, instance
 	instance.Status.NumberReady = dset.GetDaemonSet().Status.NumberReady
 
 	// Define a new DaemonSet object for OVS (ovsdb-server + ovs-vswitchd)
+	ovsDaemonSet := ovncontroller.CreateOVSDaemonSet(instance, inputHash, ovsServiceLabels, serviceAnnotations, additionalNetworks.Resources)
+	if instance.Spec.ImageRollout != nil {
+		templateHashes[ovnv1.ServiceNameOVS], err = ovncontroller.SetTemplateHash(ovsDaemonSet)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	err = ovncontroller.EnsureUpdateStrategy(ctx, r.Client, instance, ovsDaemonSet.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	ovsdset := daemonset.NewDaemonSet(
-		ovncontroller.CreateOVSDaemonSet(instance, inputHash, ovsServiceLabels, serviceAnnotations),
+		ovsDaemonSet,
 		time.Duration(5)*time.Second,
 	)
 
@@ -598,71 +716,239 @@ func (r *OVNControllerReconciler) reconcileNormal(ctx context.Context, instance
 	}
 	// create DaemonSet - end
 
+	nodeStatus, err := ovncontroller.GetNodeStatus(ctx, r.Client, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for node, status := range nodeStatus {
+		r.recordFlowRestore(instance, node, instance.Status.Nodes[node].FlowRestore, status.FlowRestore)
+	}
+	instance.Status.Nodes = nodeStatus
+
+	// the chassis run the OVN image once both DaemonSets rolled out and every
+	// node reports the same version, ovn-northd and the DBs wait for it
+	instance.Status.ChassisOVNVersion = ovncontroller.GetChassisOVNVersion(instance.Status.Nodes, instance.Spec.OvnContainerImage)
+	if ovncontroller.IsDaemonSetRolledOut(dset.GetDaemonSet()) && ovncontroller.IsDaemonSetRolledOut(ovsdset.GetDaemonSet()) &&
+		(len(instance.Status.Nodes) == 0 || instance.Status.ChassisOVNVersion != "") {
+		instance.Status.ChassisOvnImage = instance.Spec.OvnContainerImage
+	}
+	instance.Status.UpgradePhase, err = ovnv1.GetUpgradePhase(ctx, helper, instance.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the progress of the rollouts is time based, while one is in progress
+	// the reconcile is requeued after requeueAfter
+	var requeueAfter time.Duration
+
+	// replace the pods running an outdated template - start
+	if instance.Spec.ImageRollout == nil {
+		instance.Status.ImageRollout = nil
+		instance.Status.Conditions.MarkTrue(
+			ovnv1.OVNControllerImageRolloutReadyCondition,
+			ovnv1.OVNControllerImageRolloutReadyMessage)
+	} else {
+		err = ovncontroller.RolloutImages(ctx, r.Client, instance, templateHashes)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				ovnv1.OVNControllerImageRolloutReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				ovnv1.OVNControllerImageRolloutReadyErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+		imageRollout := instance.Status.ImageRollout
+		switch {
+		case imageRollout.Paused:
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				ovnv1.OVNControllerImageRolloutReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				ovnv1.OVNControllerImageRolloutReadyPausedMessage,
+				ovncontroller.GetUnhealthyNodes(imageRollout)))
+			requeueAfter = time.Second * 15
+		case imageRollout.Phase != ovnv1.ImageRolloutPhaseComplete:
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				ovnv1.OVNControllerImageRolloutReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				ovnv1.OVNControllerImageRolloutReadyRunningMessage,
+				imageRollout.Phase,
+				imageRollout.UpdatedNodes,
+				imageRollout.TotalNodes))
+			// check the current wave again at the latest when its progress deadline expires
+			requeueAfter = time.Second * 15
+			if imageRollout.WaveStartTime != nil {
+				deadline := time.Duration(instance.Spec.ImageRollout.ProgressDeadlineSeconds) * time.Second
+				remaining := deadline - time.Since(imageRollout.WaveStartTime.Time) + time.Second
+				requeueAfter = min(requeueAfter, max(remaining, time.Second))
+			}
+		default:
+			instance.Status.Conditions.MarkTrue(
+				ovnv1.OVNControllerImageRolloutReadyCondition,
+				ovnv1.OVNControllerImageRolloutReadyMessage)
+		}
+	}
+	// replace the pods running an outdated template - end
+
 	sbCluster, err := ovnv1.GetDBClusterByType(ctx, helper, instance.Namespace, map[string]string{}, ovnv1.SBDBType)
 	if err != nil {
 		Log.Info("No SB OVNDBCluster defined. Exiting reconcile.")
 		return ctrl.Result{}, nil
 	}
 
-	// create OVN Config Job - start
-	// Waits for OVS pods to run the configJob which basically will set config into OVS database
-	if instance.Status.OVSNumberReady != instance.Status.DesiredNumberScheduled {
-		Log.Info("OVS DaemonSet not ready yet. Configuration job cannot be started.")
-		return ctrl.Result{Requeue: true}, nil
+	// create OVN agent config - start
+	// The ovn-config-agent container of each ovn-controller pod applies this config to OVS
+	// and reports the generation it applied back as a pod annotation
+	generation, agentConfig, err := ovncontroller.AgentConfig(instance, sbCluster)
+	if err != nil {
+		Log.Error(err, "Failed to render OVN controller agent config")
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.ServiceConfigReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.ServiceConfigReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
 	}
-	jobsDef, err := ovncontroller.ConfigJob(ctx, r.Client, instance, sbCluster, ovnServiceLabels)
+	// nodes are moved to a new generation following spec.configRollout
+	agentConfigData, err := ovncontroller.RolloutConfig(ctx, r.Client, instance, generation, agentConfig)
 	if err != nil {
-		Log.Error(err, "Failed to create OVN controller configuration Job")
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNControllerConfigRolloutReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNControllerConfigRolloutReadyErrorMessage,
+			err.Error()))
 		return ctrl.Result{}, err
 	}
-	for _, jobDef := range jobsDef {
-		configHashKey := ovnv1.OVNConfigHash + "-" + jobDef.Spec.Template.Spec.NodeName
-		configHash := instance.Status.Hash[configHashKey]
-		configJob := job.NewJob(
-			jobDef,
-			configHashKey,
-			false,
-			time.Duration(5)*time.Second,
-			configHash,
-		)
-		ctrlResult, err = configJob.DoJob(ctx, helper)
-		if (ctrlResult != ctrl.Result{}) {
-			instance.Status.Conditions.Set(
-				condition.FalseCondition(
-					condition.ServiceConfigReadyCondition,
-					condition.RequestedReason,
-					condition.SeverityInfo,
-					condition.ServiceConfigReadyMessage,
-				),
-			)
-			return ctrlResult, nil
-		}
-		if err != nil {
-			Log.Error(err, "Failed to configure OVN controller")
-			instance.Status.Conditions.Set(
-				condition.FalseCondition(
-					condition.ServiceConfigReadyCondition,
-					condition.RequestedReason,
-					condition.SeverityInfo,
-					condition.ServiceConfigReadyErrorMessage,
-					err.Error(),
-				),
-			)
-			return ctrl.Result{}, err
-		}
-		if configJob.HasChanged() {
-			instance.Status.Hash[configHashKey] = configJob.GetHash()
-			Log.Info(fmt.Sprintf("Job %s hash added - %s", jobDef.Name, instance.Status.Hash[configHashKey]))
+	err = r.generateAgentConfigMap(ctx, helper, instance, agentConfigData)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.ServiceConfigReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.ServiceConfigReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	// drop the per node hashes of the config jobs used before the agent
+	for key := range instance.Status.Hash {
+		if strings.HasPrefix(key, ovnv1.OVNConfigHash+"-") {
+			delete(instance.Status.Hash, key)
 		}
 	}
+	instance.Status.Hash[ovnv1.OVNConfigHash] = generation
 	instance.Status.Conditions.MarkTrue(condition.ServiceConfigReadyCondition, condition.ServiceConfigReadyMessage)
-	// create OVN Config Job - end
+	// create OVN agent config - end
+
+	rollout := instance.Status.ConfigRollout
+	totalNodes := int(rollout.TotalNodes)
+	switch {
+	case rollout.Halted:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNControllerConfigRolloutReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNControllerConfigRolloutReadyHaltedMessage,
+			len(rollout.FailedNodes),
+			ovncontroller.GetRolloutFailedNodes(rollout)))
+	case int(rollout.UpdatedNodes)+len(rollout.FailedNodes) < totalNodes:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNControllerConfigRolloutReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNControllerConfigRolloutReadyRunningMessage,
+			rollout.UpdatedNodes,
+			totalNodes))
+		// pending nodes have to be checked against the progress deadline
+		Log.Info("Config rollout in progress")
+		if requeueAfter == 0 || requeueAfter > time.Second*30 {
+			requeueAfter = time.Second * 30
+		}
+	case len(rollout.FailedNodes) > 0:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNControllerConfigRolloutReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNControllerConfigRolloutReadyFailedMessage,
+			len(rollout.FailedNodes),
+			ovncontroller.GetRolloutFailedNodes(rollout)))
+	default:
+		instance.Status.Conditions.MarkTrue(
+			ovnv1.OVNControllerConfigRolloutReadyCondition,
+			ovnv1.OVNControllerConfigRolloutReadyMessage)
+	}
+
+	if requeueAfter > 0 {
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 
 	Log.Info("Reconciled Service successfully")
 
 	return ctrl.Result{}, nil
 }
 
+// recordFlowRestore - emits an Event for a flow restore a node reported since the last reconcile,
+// so the restarts of ovs-vswitchd which were not hitless stand out
+func (r *OVNControllerReconciler) recordFlowRestore(
+	instance *ovnv1.OVNController,
+	node string,
+	previous *ovnv1.OVNControllerFlowRestoreStatus,
+	current *ovnv1.OVNControllerFlowRestoreStatus,
+) {
+	if current == nil || (previous != nil && previous.Time.Equal(&current.Time)) {
+		return
+	}
+
+	switch {
+	case current.Result == ovnv1.FlowRestoreFailed:
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FlowRestoreFailed",
+			"Restore of %d saved flows failed on node %s, %d flows present",
+			current.SavedFlows, node, current.RestoredFlows)
+	case !current.IsHitless():
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FlowRestoreIncomplete",
+			"Restored %d of %d saved flows on node %s",
+			current.RestoredFlows, current.SavedFlows, node)
+	default:
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "FlowRestoreSucceeded",
+			"Restored all %d saved flows on node %s", current.SavedFlows, node)
+	}
+}
+
+// reconcileHostStateDir - sets Status.HostStateDir to the host directory the pods have to use.
+// The existing state is not migrated, so a change of spec.hostStateDir is refused while
+// pods are scheduled and the previous directory stays in use.
+func (r *OVNControllerReconciler) reconcileHostStateDir(ctx context.Context, instance *ovnv1.OVNController) {
+	Log := r.GetLogger(ctx)
+
+	hostStateDir := instance.Spec.HostStateDir
+	if hostStateDir == "" {
+		hostStateDir = ovnv1.OVNControllerHostStateDir
+	}
+
+	if instance.Status.HostStateDir != "" && instance.Status.HostStateDir != hostStateDir &&
+		instance.Status.DesiredNumberScheduled > 0 {
+		Log.Info(fmt.Sprintf("Refusing hostStateDir change from %s to %s", instance.Status.HostStateDir, hostStateDir))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNControllerHostStateDirReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNControllerHostStateDirChangeRefusedMessage,
+			instance.Status.HostStateDir,
+			hostStateDir,
+			instance.Status.DesiredNumberScheduled))
+		return
+	}
+
+	instance.Status.HostStateDir = hostStateDir
+	instance.Status.Conditions.MarkTrue(
+		ovnv1.OVNControllerHostStateDirReadyCondition,
+		ovnv1.OVNControllerHostStateDirReadyMessage,
+		hostStateDir)
+}
+
 // generateServiceConfigMaps - create configmaps which hold scripts and service configuration
 func (r *OVNControllerReconciler) generateServiceConfigMaps(
 	ctx context.Context,
@@ -674,11 +960,17 @@ func (r *OVNControllerReconciler) generateServiceConfigMaps(
 	cmLabels := labels.GetLabels(instance, labels.GetGroupLabel(ovnv1.ServiceNameOVNController), map[string]string{})
 
 	templateParameters := make(map[string]interface{})
+	encapNICs := []string{}
 	if instance.Spec.NetworkAttachment != "" {
-		templateParameters["OVNEncapNIC"] = nad.GetNetworkIFName(instance.Spec.NetworkAttachment)
+		encapNICs = append(encapNICs, nad.GetNetworkIFName(instance.Spec.NetworkAttachment))
 	} else {
-		templateParameters["OVNEncapNIC"] = "eth0"
+		encapNICs = append(encapNICs, "eth0")
 	}
+	// the first NIC provides the default encap IP
+	for _, netAtt := range instance.Spec.EncapNetworkAttachments {
+		encapNICs = append(encapNICs, nad.GetNetworkIFName(netAtt))
+	}
+	templateParameters["OVNEncapNICs"] = strings.Join(encapNICs, " ")
 	cms := []util.Template{
 		// ScriptsConfigMap
 		{
@@ -693,6 +985,31 @@ func (r *OVNControllerReconciler) generateServiceConfigMaps(
 	return configmap.EnsureConfigMaps(ctx, h, instance, cms, envVars)
 }
 
+// generateAgentConfigMap - create the configmap applied by the ovn-config-agent containers. Its
+// hash is not part of the input hash, the agents pick up changes without restarting the pods.
+// The data is stored as is, values like extraExternalIDs must not go through template expansion.
+func (r *OVNControllerReconciler) generateAgentConfigMap(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *ovnv1.OVNController,
+	data map[string]string,
+) error {
+	cmLabels := labels.GetLabels(instance, labels.GetGroupLabel(ovnv1.ServiceNameOVNController), map[string]string{})
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ovncontroller.AgentConfigMapName(instance),
+			Namespace: instance.Namespace,
+		},
+	}
+	_, err := controllerutil.CreateOrPatch(ctx, r.Client, cm, func() error {
+		cm.Labels = util.MergeStringMaps(cm.Labels, cmLabels)
+		cm.Data = data
+		return controllerutil.SetControllerReference(instance, cm, h.GetScheme())
+	})
+	return err
+}
+
 // createHashOfInputHashes - creates a hash of hashes which gets added to the resources which requires a restart
 // if any of the input resources change, like configs, passwords, ...
 //
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -28,10 +29,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -54,6 +57,7 @@ import (
 	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
 	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovndbcluster"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
@@ -62,8 +66,9 @@ import (
 // OVNDBClusterReconciler reconciles a OVNDBCluster object
 type OVNDBClusterReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient     kubernetes.Interface
+	Scheme      *runtime.Scheme
+	PodExecutor ovn_common.PodExecutor
 }
 
 // GetClient -
@@ -87,14 +92,17 @@ func (r *OVNDBClusterReconciler) GetLogger(ctx context.Context) logr.Logger {
 }
 
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndbclusters,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovnnorthds,verbs=get;list;watch;
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovncontroller,verbs=watch;
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndbclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndbclusters/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;patch;update;delete;
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;patch;update;delete;
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;patch;update;delete;
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create;
 //+kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list;watch
 //+kubebuilder:rbac:groups=network.openstack.org,resources=dnsdata,verbs=get;list;watch;create;update;patch;delete
 
@@ -157,6 +165,7 @@ func (r *OVNDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		condition.UnknownCondition(condition.RoleReadyCondition, condition.InitReason, condition.RoleReadyInitMessage),
 		condition.UnknownCondition(condition.RoleBindingReadyCondition, condition.InitReason, condition.RoleBindingReadyInitMessage),
 		condition.UnknownCondition(condition.TLSInputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNUpgradeOrderReadyCondition, condition.InitReason, ovnv1.OVNUpgradeOrderReadyInitMessage),
 	)
 
 	instance.Status.Conditions.Init(&cl)
@@ -171,18 +180,9 @@ func (r *OVNDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// Always patch the instance status when exiting this function so we can persist any changes.
 	defer func() {
-		// update the Ready condition based on the sub conditions
-		if instance.Status.Conditions.AllSubConditionIsTrue() {
-			instance.Status.Conditions.MarkTrue(
-				condition.ReadyCondition, condition.ReadyMessage)
-		} else {
-			// something is not ready so reset the Ready condition
-			instance.Status.Conditions.MarkUnknown(
-				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
-			// and recalculate it based on the state of the rest of the conditions
-			instance.Status.Conditions.Set(
-				instance.Status.Conditions.Mirror(condition.ReadyCondition))
-		}
+		// update the Ready condition based on the sub conditions, clients dropping their
+		// connections does not make the DB cluster not ready
+		ovn_common.UpdateReadyCondition(&instance.Status.Conditions, ovnv1.OVNDBClusterClientConnectionsStableCondition)
 		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
 		err := helper.PatchInstance(ctx, instance)
 		if err != nil {
@@ -237,19 +237,66 @@ func (r *OVNDBClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&batchv1.Job{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&infranetworkv1.DNSData{}).
 		Watches(&ovnv1.OVNController{}, handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient()))).
+		Watches(&ovnv1.OVNNorthd{}, handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient()))).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		// the connections get checked as soon as clients go away or come back
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForClientPod),
+			builder.WithPredicates(clientPodPredicate, clientPodChangedPredicate),
+		).
 		Complete(r)
 }
 
+// clientPodPredicate - passes the events of the ovn-northd and ovn-controller pods
+var clientPodPredicate = predicate.NewPredicateFuncs(func(o client.Object) bool {
+	service := o.GetLabels()[common.AppSelector]
+	return service == ovnv1.ServiceNameOVNNorthd || service == ovnv1.ServiceNameOVNController
+})
+
+// clientPodChangedPredicate - passes the deletions of pods and the updates changing their
+// Ready condition. A created pod is not ready yet.
+var clientPodChangedPredicate = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return isPodReady(e.ObjectOld) != isPodReady(e.ObjectNew)
+	},
+}
+
+func (r *OVNDBClusterReconciler) findObjectsForClientPod(ctx context.Context, pod client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	crList := &ovnv1.OVNDBClusterList{}
+	if err := r.Client.List(ctx, crList, client.InNamespace(pod.GetNamespace())); err != nil {
+		return requests
+	}
+	for _, item := range crList.Items {
+		if !slices.Contains(ovndbcluster.ClientServices(item.Spec.DBType), pod.GetLabels()[common.AppSelector]) {
+			continue
+		}
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
+
 func (r *OVNDBClusterReconciler) findObjectsForSrc(ctx context.Context, src client.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 
@@ -517,9 +564,51 @@ func (r *OVNDBClusterReconciler) reconcileNormal(ctx context.Context, instance *
 	} else if (ctrlResult != ctrl.Result{}) {
 		return ctrlResult, nil
 	}
+
+	// the DBs are only updated once ovn-controller runs the OVN version of the new image on
+	// every chassis
+	targetVersion := ""
+	if instance.Status.ContainerImage != "" && instance.Status.ContainerImage != instance.Spec.ContainerImage {
+		instance.Status.TargetOVNVersion, err = ovn_common.GetOVNVersion(ctx, helper, instance,
+			instance.Spec.ContainerImage, instance.RbacResourceName(), instance.Status.TargetOVNVersion)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if instance.Status.TargetOVNVersion != nil {
+			targetVersion = instance.Status.TargetOVNVersion.Version
+		}
+	} else {
+		instance.Status.TargetOVNVersion = nil
+	}
+	containerImage, held, err := ovnv1.GetCentralContainerImage(ctx, helper, instance.Namespace,
+		instance.Spec.ContainerImage, instance.Status.ContainerImage, targetVersion)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	switch {
+	case held && targetVersion == "":
+		Log.Info(fmt.Sprintf("Update to %s held until its OVN version is known", instance.Spec.ContainerImage))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNUpgradeOrderReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNUpgradeOrderReadyVersionMessage,
+			instance.Spec.ContainerImage))
+	case held:
+		Log.Info(fmt.Sprintf("Update to %s held until ovn-controller runs OVN %s", instance.Spec.ContainerImage, targetVersion))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNUpgradeOrderReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNUpgradeOrderReadyHeldMessage,
+			instance.Spec.ContainerImage, targetVersion))
+	default:
+		instance.Status.Conditions.MarkTrue(ovnv1.OVNUpgradeOrderReadyCondition, ovnv1.OVNUpgradeOrderReadyMessage)
+	}
+
 	// Define a new Statefulset object
 	sfset := statefulset.NewStatefulSet(
-		ovndbcluster.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations),
+		ovndbcluster.StatefulSet(instance, inputHash, serviceLabels, serviceAnnotations, containerImage),
 		time.Duration(5)*time.Second,
 	)
 
@@ -542,6 +631,11 @@ func (r *OVNDBClusterReconciler) reconcileNormal(ctx context.Context, instance *
 	}
 
 	instance.Status.ReadyCount = sfset.GetStatefulSet().Status.ReadyReplicas
+	instance.Status.ContainerImage = containerImage
+	instance.Status.UpgradePhase, err = ovnv1.GetUpgradePhase(ctx, helper, instance.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// verify if network attachment matches expectations
 	networkReady, networkAttachmentStatus, err := nad.VerifyNetworkStatusFromAnnotation(ctx, helper, networkAttachments, serviceLabels, instance.Status.ReadyCount)
@@ -636,8 +730,100 @@ func (r *OVNDBClusterReconciler) reconcileNormal(ctx context.Context, instance *
 		}
 
 	}
+
+	// the OVN version Job of the new image is checked until it completed
+	if instance.Status.ContainerImage != "" && instance.Status.ContainerImage != instance.Spec.ContainerImage &&
+		instance.Status.TargetOVNVersion == nil {
+		return ctrl.Result{RequeueAfter: ovn_common.OVNVersionCheckInterval}, nil
+	}
+
+	if instance.Status.ReadyCount == 0 {
+		Log.Info("Reconciled Service successfully")
+		return ctrl.Result{}, nil
+	}
+	connectionsCheckWait := r.reconcileConnections(ctx, instance, helper, serviceLabels)
+
 	Log.Info("Reconciled Service successfully")
-	return ctrl.Result{}, nil
+	// clients connect and disconnect without any change of the pods
+	return ctrl.Result{RequeueAfter: connectionsCheckWait}, nil
+}
+
+// reconcileConnections - reports the connections of each member, and whether the client
+// connections dropped sharply since the previous check. They get checked as soon as the ready
+// client pods change, at most every ConnectionsRetryInterval, and every ConnectionsCheckInterval
+// otherwise. Returns the time until the next check.
+func (r *OVNDBClusterReconciler) reconcileConnections(
+	ctx context.Context,
+	instance *ovnv1.OVNDBCluster,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+) time.Duration {
+	Log := r.GetLogger(ctx)
+
+	clientPodsHash, err := ovndbcluster.GetClientPodsHash(ctx, r.Client, instance)
+	if err != nil {
+		Log.Info(fmt.Sprintf("Could not list the client pods: %s", err))
+		return ovndbcluster.ConnectionsRetryInterval
+	}
+	// the status patch of a check triggers a reconcile, which must not compare with it again
+	if checkTime := instance.Status.ConnectionsCheckTime; checkTime != nil {
+		interval := ovndbcluster.ConnectionsCheckInterval
+		if clientPodsHash != instance.Status.Hash[ovndbcluster.ClientPodsHash] {
+			interval = ovndbcluster.ConnectionsRetryInterval
+		}
+		if wait := checkTime.Add(interval).Sub(time.Now()); wait > 0 {
+			return wait
+		}
+	}
+
+	podList, err := ovndbcluster.OVNDBPods(ctx, instance, helper, serviceLabels)
+	if err != nil {
+		Log.Info(fmt.Sprintf("Could not list the DB pods: %s", err))
+		return ovndbcluster.ConnectionsRetryInterval
+	}
+	connections, unqueried := ovndbcluster.GetConnections(ctx, r.PodExecutor, instance, podList.Items)
+	if len(connections) == 0 {
+		// no member could be queried, keep the previous check
+		return ovndbcluster.ConnectionsCheckInterval
+	}
+	// a member which could not be queried keeps its previous connections, its clients did
+	// not go away. Without previous connections the client connections are not compared.
+	compare := true
+	for _, name := range unqueried {
+		if previous, ok := instance.Status.Connections[name]; ok {
+			connections[name] = previous
+		} else {
+			compare = false
+		}
+	}
+	if len(unqueried) > 0 {
+		Log.Info(fmt.Sprintf("Could not query the connections of %s", strings.Join(unqueried, ",")))
+	}
+
+	clients := ovndbcluster.GetClientConnections(connections)
+	switch {
+	case !compare:
+		// the condition and the client connections of the previous check are kept
+	case ovndbcluster.IsConnectionsDrop(instance.Status.ClientConnections, clients):
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNDBClusterClientConnectionsStableCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNDBClusterClientConnectionsDroppedMessage,
+			instance.Status.ClientConnections, clients))
+		Log.Info(fmt.Sprintf("Client connections dropped from %d to %d", instance.Status.ClientConnections, clients))
+	default:
+		instance.Status.Conditions.MarkTrue(
+			ovnv1.OVNDBClusterClientConnectionsStableCondition, ovnv1.OVNDBClusterClientConnectionsStableMessage, clients)
+	}
+
+	instance.Status.Connections = connections
+	if compare {
+		instance.Status.ClientConnections = clients
+	}
+	instance.Status.ConnectionsCheckTime = ptr.To(metav1.Now())
+	instance.Status.Hash[ovndbcluster.ClientPodsHash] = clientPodsHash
+	return ovndbcluster.ConnectionsCheckInterval
 }
 
 func getPodIPInNetwork(ovnPod corev1.Pod, namespace string, networkAttachment string) (string, error) {
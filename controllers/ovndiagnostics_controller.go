@@ -0,0 +1,220 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
+	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovndiagnostics"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// maxConcurrentDiagnostics - OVNDiagnostics whose commands run at the same time. A reconcile
+// waits for the commands it runs, so that a slow OVNDiagnostics does not hold the others back.
+const maxConcurrentDiagnostics = 3
+
+// OVNDiagnosticsReconciler reconciles a OVNDiagnostics object
+type OVNDiagnosticsReconciler struct {
+	client.Client
+	Kclient     kubernetes.Interface
+	Scheme      *runtime.Scheme
+	PodExecutor ovn_common.PodExecutor
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *OVNDiagnosticsReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("OVNDiagnostics")
+}
+
+//+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndiagnostics,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndiagnostics/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndiagnostics/finalizers,verbs=update;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create;
+
+// Reconcile - runs the commands of an OVNDiagnostics once per generation
+func (r *OVNDiagnosticsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	instance := &ovnv1.OVNDiagnostics{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// the output ConfigMap is garbage collected
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// the commands of this generation already ran
+	if instance.Status.ObservedGeneration == instance.Generation && instance.Status.CompletionTime != nil {
+		return ctrl.Result{}, nil
+	}
+
+	helper, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	savedConditions := instance.Status.Conditions.DeepCopy()
+	if instance.Status.ObservedGeneration != instance.Generation {
+		// the result of the previous generation does not apply anymore
+		instance.Status.Conditions = condition.Conditions{}
+		instance.Status.CompletionTime = nil
+		instance.Status.FailedCommands = nil
+	}
+	cl := condition.CreateList(
+		condition.UnknownCondition(ovnv1.OVNDiagnosticsCompletedCondition, condition.InitReason, ovnv1.OVNDiagnosticsCompletedInitMessage),
+	)
+	instance.Status.Conditions.Init(&cl)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	defer func() {
+		if instance.Status.Conditions.AllSubConditionIsTrue() {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := helper.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	return r.reconcileNormal(ctx, instance, helper)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OVNDiagnosticsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ovnv1.OVNDiagnostics{}).
+		Owns(&corev1.ConfigMap{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentDiagnostics}).
+		Complete(r)
+}
+
+func (r *OVNDiagnosticsReconciler) reconcileNormal(
+	ctx context.Context,
+	instance *ovnv1.OVNDiagnostics,
+	helper *helper.Helper,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	// only read only commands run, refused ones are not retried until the spec changes
+	refused := ovndiagnostics.GetRefusedCommands(instance)
+	if len(refused) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNDiagnosticsCompletedCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			ovnv1.OVNDiagnosticsCompletedRefusedMessage,
+			instance.Spec.Target,
+			strings.Join(refused, ",")))
+		return ctrl.Result{}, nil
+	}
+
+	commands, skipped, err := ovndiagnostics.GetCommands(ctx, r.Client, instance)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNDiagnosticsCompletedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNDiagnosticsCompletedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if len(commands) == 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNDiagnosticsCompletedCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNDiagnosticsCompletedNoPodsMessage,
+			instance.Spec.Target))
+		return ctrl.Result{RequeueAfter: time.Duration(5) * time.Second}, nil
+	}
+
+	for _, command := range commands {
+		Log.Info(fmt.Sprintf("Running %s in pod %s", strings.Join(command.Command, " "), command.Pod.Name))
+	}
+	result := ovndiagnostics.Run(ctx, r.PodExecutor, commands)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name + "-output",
+			Namespace: instance.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrPatch(ctx, r.Client, cm, func() error {
+		cm.Labels = map[string]string{"ovn.openstack.org/diagnostics": instance.Name}
+		cm.Data = result.Output
+		return controllerutil.SetControllerReference(instance, cm, helper.GetScheme())
+	})
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNDiagnosticsCompletedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNDiagnosticsCompletedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	instance.Status.OutputConfigMap = cm.Name
+	instance.Status.FailedCommands = result.Failed
+	instance.Status.TruncatedCommands = result.Truncated
+	instance.Status.SkippedPods = skipped
+	instance.Status.CompletionTime = ptr.To(metav1.Now())
+	instance.Status.Conditions.MarkTrue(
+		ovnv1.OVNDiagnosticsCompletedCondition, ovnv1.OVNDiagnosticsCompletedMessage, len(commands), len(result.Failed))
+
+	return ctrl.Result{}, nil
+}
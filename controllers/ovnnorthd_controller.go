@@ -18,7 +18,9 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/fields"
@@ -29,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -37,24 +40,32 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/configmap"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/deployment"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
 	common_rbac "github.com/openstack-k8s-operators/lib-common/modules/common/rbac"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
 	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovnnorthd"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // OVNNorthdReconciler reconciles a OVNNorthd object
 type OVNNorthdReconciler struct {
 	client.Client
-	Kclient kubernetes.Interface
-	Scheme  *runtime.Scheme
+	Kclient     kubernetes.Interface
+	Scheme      *runtime.Scheme
+	PodExecutor ovn_common.PodExecutor
 }
 
 // GetClient -
@@ -76,11 +87,14 @@ func (r *OVNNorthdReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovnnorthds/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovnnorthds/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndbclusters,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovncontrollers,verbs=get;list;watch;
 //+kubebuilder:rbac:groups=ovn.openstack.org,resources=ovndbclusters/status,verbs=get;list;watch;
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;patch;update;delete;
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;patch;update;delete;
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create;
 
 // service account, role, rolebinding
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch
@@ -133,30 +147,30 @@ func (r *OVNNorthdReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	// initialize conditions used later as Status=Unknown
 	cl := condition.CreateList(
 		condition.UnknownCondition(condition.InputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNDBClusterReadyCondition, condition.InitReason, ovnv1.OVNDBClusterReadyInitMessage),
+		condition.UnknownCondition(condition.ServiceConfigReadyCondition, condition.InitReason, condition.ServiceConfigReadyInitMessage),
 		condition.UnknownCondition(condition.DeploymentReadyCondition, condition.InitReason, condition.DeploymentReadyInitMessage),
 		condition.UnknownCondition(condition.ServiceAccountReadyCondition, condition.InitReason, condition.ServiceAccountReadyInitMessage),
 		condition.UnknownCondition(condition.RoleReadyCondition, condition.InitReason, condition.RoleReadyInitMessage),
 		condition.UnknownCondition(condition.RoleBindingReadyCondition, condition.InitReason, condition.RoleBindingReadyInitMessage),
 		condition.UnknownCondition(condition.TLSInputReadyCondition, condition.InitReason, condition.InputReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNUpgradeOrderReadyCondition, condition.InitReason, ovnv1.OVNUpgradeOrderReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNNorthdActiveReadyCondition, condition.InitReason, ovnv1.OVNNorthdActiveReadyInitMessage),
+		condition.UnknownCondition(ovnv1.OVNNorthdNBGlobalReadyCondition, condition.InitReason, ovnv1.OVNNorthdNBGlobalReadyInitMessage),
 	)
 
 	instance.Status.Conditions.Init(&cl)
 	instance.Status.ObservedGeneration = instance.Generation
 
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+
 	// Always patch the instance status when exiting this function so we can persist any changes.
 	defer func() {
-		// update the Ready condition based on the sub conditions
-		if instance.Status.Conditions.AllSubConditionIsTrue() {
-			instance.Status.Conditions.MarkTrue(
-				condition.ReadyCondition, condition.ReadyMessage)
-		} else {
-			// something is not ready so reset the Ready condition
-			instance.Status.Conditions.MarkUnknown(
-				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
-			// and recalculate it based on the state of the rest of the conditions
-			instance.Status.Conditions.Set(
-				instance.Status.Conditions.Mirror(condition.ReadyCondition))
-		}
+		// update the Ready condition based on the sub conditions, the instances being
+		// paused or not does not make the service not ready
+		ovn_common.UpdateReadyCondition(&instance.Status.Conditions, ovnv1.OVNNorthdPausedCondition)
 		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
 		err := helper.PatchInstance(ctx, instance)
 		if err != nil {
@@ -209,10 +223,26 @@ func (r *OVNNorthdReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&ovnv1.OVNNorthd{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&batchv1.Job{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
-		Watches(&ovnv1.OVNDBCluster{}, handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient()))).
+		// the endpoints of the DB clusters are passed to ovn-northd, their images drive the upgrade phase
+		Watches(
+			&ovnv1.OVNDBCluster{},
+			handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient())),
+			builder.WithPredicates(dbClusterChangedPredicate),
+		).
+		// image updates are held until ovn-controller got updated
+		Watches(&ovnv1.OVNController{}, handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient()))).
+		// the ovn-northd instances are queried again when their pods change
+		Watches(
+			&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(ovnv1.OVNCRNamespaceMapFunc(crs, mgr.GetClient())),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+				return o.GetLabels()[common.AppSelector] == ovnv1.ServiceNameOVNNorthd
+			})),
+		).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.findObjectsForSrc),
@@ -221,6 +251,25 @@ func (r *OVNNorthdReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// dbClusterChangedPredicate - OVNDBCluster changes ovn-northd depends on
+var dbClusterChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldCluster, ok := e.ObjectOld.(*ovnv1.OVNDBCluster)
+		if !ok {
+			return false
+		}
+		newCluster, ok := e.ObjectNew.(*ovnv1.OVNDBCluster)
+		if !ok {
+			return false
+		}
+		return oldCluster.Spec.DBType != newCluster.Spec.DBType ||
+			oldCluster.Spec.ContainerImage != newCluster.Spec.ContainerImage ||
+			oldCluster.Status.InternalDBAddress != newCluster.Status.InternalDBAddress ||
+			oldCluster.Status.DBAddress != newCluster.Status.DBAddress ||
+			oldCluster.Status.ContainerImage != newCluster.Status.ContainerImage
+	},
+}
+
 func (r *OVNNorthdReconciler) findObjectsForSrc(ctx context.Context, src client.Object) []reconcile.Request {
 	requests := []reconcile.Request{}
 
@@ -335,14 +384,34 @@ func (r *OVNNorthdReconciler) reconcileNormal(ctx context.Context, instance *ovn
 		return ctrlResult, nil
 	}
 
-	nbEndpoint, err := getInternalEndpoint(ctx, helper, instance, ovnv1.NBDBType)
-	if err != nil {
-		return ctrlResult, err
+	nbEndpoint, err := getEndpoint(ctx, helper, instance, ovnv1.NBDBType)
+	var sbEndpoint string
+	if err == nil {
+		sbEndpoint, err = getEndpoint(ctx, helper, instance, ovnv1.SBDBType)
 	}
-	sbEndpoint, err := getInternalEndpoint(ctx, helper, instance, ovnv1.SBDBType)
 	if err != nil {
-		return ctrlResult, err
+		if errors.Is(err, ovnv1.ErrDBClusterNotFound) || errors.Is(err, ovnv1.ErrDBEndpointNotReady) {
+			// the OVNDBCluster watch reconciles once they are there
+			Log.Info(fmt.Sprintf("Waiting for the DB clusters: %s", err))
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				ovnv1.OVNDBClusterReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				ovnv1.OVNDBClusterReadyWaitingMessage,
+				err.Error()))
+			return ctrl.Result{}, nil
+		}
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNDBClusterReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNDBClusterReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
 	}
+	instance.Status.NBConnection = nbEndpoint
+	instance.Status.SBConnection = sbEndpoint
+	instance.Status.Conditions.MarkTrue(ovnv1.OVNDBClusterReadyCondition, ovnv1.OVNDBClusterReadyMessage)
 
 	envVars := make(map[string]env.Setter)
 
@@ -407,9 +476,79 @@ func (r *OVNNorthdReconciler) reconcileNormal(ctx context.Context, instance *ovn
 	// all cert input checks out so report InputReady
 	instance.Status.Conditions.MarkTrue(condition.TLSInputReadyCondition, condition.InputReadyMessage)
 
+	//
+	// create Configmap with the scripts of the NB_Global options Job
+	//
+	configMapVars := make(map[string]env.Setter)
+	err = r.generateServiceConfigMaps(ctx, helper, instance, &configMapVars)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.ServiceConfigReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.ServiceConfigReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	// the endpoints are part of the input hash, a change of them, e.g. on scale out of the
+	// DB clusters or when TLS gets enabled, rolls the Deployment like any other input change
+	inputHash, err := r.createHashOfInputHashes(ctx, instance, nbEndpoint, sbEndpoint, envVars)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.ServiceConfigReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.ServiceConfigReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.ServiceConfigReadyCondition, condition.ServiceConfigReadyMessage)
+
+	// ovn-northd is only updated once ovn-controller runs the OVN version of the new image on
+	// every chassis
+	targetVersion := ""
+	if instance.Status.ContainerImage != "" && instance.Status.ContainerImage != instance.Spec.ContainerImage {
+		instance.Status.TargetOVNVersion, err = ovn_common.GetOVNVersion(ctx, helper, instance,
+			instance.Spec.ContainerImage, instance.RbacResourceName(), instance.Status.TargetOVNVersion)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if instance.Status.TargetOVNVersion != nil {
+			targetVersion = instance.Status.TargetOVNVersion.Version
+		}
+	} else {
+		instance.Status.TargetOVNVersion = nil
+	}
+	containerImage, held, err := ovnv1.GetCentralContainerImage(ctx, helper, instance.Namespace,
+		instance.Spec.ContainerImage, instance.Status.ContainerImage, targetVersion)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	switch {
+	case held && targetVersion == "":
+		Log.Info(fmt.Sprintf("Update to %s held until its OVN version is known", instance.Spec.ContainerImage))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNUpgradeOrderReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNUpgradeOrderReadyVersionMessage,
+			instance.Spec.ContainerImage))
+	case held:
+		Log.Info(fmt.Sprintf("Update to %s held until ovn-controller runs OVN %s", instance.Spec.ContainerImage, targetVersion))
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNUpgradeOrderReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNUpgradeOrderReadyHeldMessage,
+			instance.Spec.ContainerImage, targetVersion))
+	default:
+		instance.Status.Conditions.MarkTrue(ovnv1.OVNUpgradeOrderReadyCondition, ovnv1.OVNUpgradeOrderReadyMessage)
+	}
+
 	// Define a new Deployment object
 	depl := deployment.NewDeployment(
-		ovnnorthd.Deployment(instance, serviceLabels, nbEndpoint, sbEndpoint, envVars),
+		ovnnorthd.Deployment(instance, inputHash, serviceLabels, nbEndpoint, sbEndpoint, envVars, containerImage),
 		time.Duration(5)*time.Second,
 	)
 
@@ -432,6 +571,11 @@ func (r *OVNNorthdReconciler) reconcileNormal(ctx context.Context, instance *ovn
 	}
 
 	instance.Status.ReadyCount = depl.GetDeployment().Status.ReadyReplicas
+	instance.Status.ContainerImage = containerImage
+	instance.Status.UpgradePhase, err = ovnv1.GetUpgradePhase(ctx, helper, instance.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	if instance.Status.ReadyCount > 0 {
 		instance.Status.Conditions.MarkTrue(condition.DeploymentReadyCondition, condition.DeploymentReadyMessage)
@@ -440,10 +584,361 @@ func (r *OVNNorthdReconciler) reconcileNormal(ctx context.Context, instance *ovn
 	}
 	// create Deployment - end
 
+	// the ovn-northd instances race for the SB lock, only the one holding it is active. Every
+	// status update triggers a reconcile, so they are only queried when a check is due.
+	instancesCheckWait, err := ovnnorthd.GetInstancesCheckWait(ctx, r.Client, instance, time.Now())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if instancesCheckWait == 0 {
+		instance.Status.Instances, err = ovnnorthd.GetInstances(ctx, r.Client, r.PodExecutor, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		instance.Status.InstancesCheckTime = &metav1.Time{Time: time.Now()}
+
+		// pods started while paused, e.g. rescheduled ones, run an unpaused instance
+		changed, err := ovnnorthd.PauseInstances(ctx, r.Client, r.PodExecutor, instance, instance.Status.Instances)
+		if err != nil {
+			// verified below with the state the instances report
+			Log.Info(fmt.Sprintf("Could not pause or resume the ovn-northd instances: %s", err))
+		}
+		if changed {
+			instance.Status.Instances, err = ovnnorthd.GetInstances(ctx, r.Client, r.PodExecutor, instance)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		instancesCheckWait = ovnnorthd.GetInstancesCheckInterval(instance)
+	}
+	mismatched := ovnnorthd.GetMismatchedInstances(instance.Status.Instances, instance.Spec.Paused)
+	unknown := ovnnorthd.GetUnknownInstances(instance.Status.Instances)
+	switch {
+	case len(mismatched) > 0 && instance.Spec.Paused:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNNorthdPausedCondition,
+			condition.RequestedReason,
+			condition.SeverityWarning,
+			ovnv1.OVNNorthdPausedRunningMessage,
+			strings.Join(mismatched, ",")))
+	case len(unknown) > 0 && instance.Spec.Paused:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNNorthdPausedCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNNorthdPausedUnknownMessage,
+			strings.Join(unknown, ",")))
+	case len(mismatched) > 0:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNNorthdPausedCondition,
+			condition.RequestedReason,
+			condition.SeverityWarning,
+			ovnv1.OVNNorthdResumeRunningMessage,
+			strings.Join(mismatched, ",")))
+	case instance.Spec.Paused:
+		instance.Status.Conditions.MarkTrue(
+			ovnv1.OVNNorthdPausedCondition, ovnv1.OVNNorthdPausedMessage, len(instance.Status.Instances))
+	default:
+		instance.Status.Conditions.Remove(ovnv1.OVNNorthdPausedCondition)
+	}
+
+	instance.Status.ActiveInstance = ""
+	active := ovnnorthd.GetActiveInstances(instance.Status.Instances)
+	switch {
+	// paused instances release the SB lock
+	case *instance.Spec.Replicas == 0 || instance.Spec.Paused:
+		instance.Status.Conditions.Remove(ovnv1.OVNNorthdActiveReadyCondition)
+	case len(active) == 0:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNNorthdActiveReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			ovnv1.OVNNorthdActiveReadyNoneMessage))
+	case len(active) > 1:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNNorthdActiveReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNNorthdActiveReadyMultipleMessage,
+			strings.Join(active, ",")))
+	default:
+		instance.Status.ActiveInstance = active[0]
+		instance.Status.Conditions.MarkTrue(
+			ovnv1.OVNNorthdActiveReadyCondition, ovnv1.OVNNorthdActiveReadyMessage, active[0])
+	}
+
+	r.reconcileAutoscaling(ctx, instance)
+
+	ctrlResult, err = r.reconcileNBGlobalOptions(ctx, instance, helper, serviceLabels, nbEndpoint, configMapVars, containerImage)
+	if err != nil {
+		return ctrlResult, err
+	} else if (ctrlResult != ctrl.Result{}) {
+		return ctrlResult, nil
+	}
+
+	// the OVN version Job of the new image is checked until it completed
+	if instance.Status.ContainerImage != "" && instance.Status.ContainerImage != instance.Spec.ContainerImage &&
+		instance.Status.TargetOVNVersion == nil {
+		return ctrl.Result{RequeueAfter: ovn_common.OVNVersionCheckInterval}, nil
+	}
+
 	Log.Info("Reconciled Service successfully")
+	if *instance.Spec.Replicas == 0 {
+		return ctrl.Result{}, nil
+	}
+	// the SB lock can move without any change of the pods
+	return ctrl.Result{RequeueAfter: instancesCheckWait}, nil
+}
+
+// reconcileNBGlobalOptions - applies the NB_Global options of the spec with a Job, which runs
+// again when their value in NB_Global drifted. The Job only runs once options got set.
+func (r *OVNNorthdReconciler) reconcileNBGlobalOptions(
+	ctx context.Context,
+	instance *ovnv1.OVNNorthd,
+	helper *helper.Helper,
+	serviceLabels map[string]string,
+	nbEndpoint string,
+	envVars map[string]env.Setter,
+	containerImage string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	options := instance.Spec.GetNBGlobalOptions()
+	if len(options) == 0 && instance.Status.Hash[ovnnorthd.NBGlobalHash] == "" {
+		instance.Status.NBGlobalOptions = nil
+		instance.Status.NBGlobalOptionsDrift = nil
+		instance.Status.Conditions.MarkTrue(ovnv1.OVNNorthdNBGlobalReadyCondition, ovnv1.OVNNorthdNBGlobalReadyMessage)
+		return ctrl.Result{}, nil
+	}
+
+	nbGlobalJob := job.NewJob(
+		ovnnorthd.NBGlobalJob(instance, serviceLabels, nbEndpoint, options, envVars, containerImage),
+		ovnnorthd.NBGlobalHash,
+		false,
+		time.Duration(5)*time.Second,
+		instance.Status.Hash[ovnnorthd.NBGlobalHash],
+	)
+	ctrlResult, err := nbGlobalJob.DoJob(ctx, helper)
+	if (ctrlResult != ctrl.Result{}) {
+		// a drift stays reported until the options got applied again
+		if len(instance.Status.NBGlobalOptionsDrift) == 0 {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				ovnv1.OVNNorthdNBGlobalReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityInfo,
+				ovnv1.OVNNorthdNBGlobalReadyRunningMessage))
+		}
+		return ctrlResult, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNNorthdNBGlobalReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			ovnv1.OVNNorthdNBGlobalReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	// the options just applied, or applied again after a drift, are checked right away
+	checkDue := nbGlobalJob.HasChanged() || len(instance.Status.NBGlobalOptionsDrift) > 0 ||
+		instance.Status.NBGlobalOptionsCheckTime == nil ||
+		time.Since(instance.Status.NBGlobalOptionsCheckTime.Time) >= ovnnorthd.NBGlobalCheckInterval
+	if nbGlobalJob.HasChanged() {
+		instance.Status.Hash[ovnnorthd.NBGlobalHash] = nbGlobalJob.GetHash()
+		Log.Info(fmt.Sprintf("Job %s hash added - %s", ovnnorthd.NBGlobalJobName, instance.Status.Hash[ovnnorthd.NBGlobalHash]))
+		// the Job just applied them, they get replaced by what is read back from NB_Global
+		instance.Status.NBGlobalOptions = options
+	}
+	if !checkDue {
+		instance.Status.Conditions.MarkTrue(ovnv1.OVNNorthdNBGlobalReadyCondition, ovnv1.OVNNorthdNBGlobalReadyMessage)
+		return ctrl.Result{}, nil
+	}
+
+	// the options can be changed by any NB client, e.g. by hand. Every status update triggers
+	// a reconcile, so NB_Global is only read once per NBGlobalCheckInterval.
+	applied, err := ovnnorthd.ReadNBGlobalOptions(ctx, r.Client, r.PodExecutor, instance, nbEndpoint, options)
+	if err != nil {
+		// keep the result of the previous check, the next reconcile checks again
+		Log.Info(fmt.Sprintf("Could not check the NB_Global options: %s", err))
+		return ctrl.Result{}, nil
+	}
+	if applied == nil {
+		// nothing got read while no ovn-northd pod runs, a drift stays reported
+		if len(instance.Status.NBGlobalOptionsDrift) > 0 {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				ovnv1.OVNNorthdNBGlobalReadyCondition,
+				condition.RequestedReason,
+				condition.SeverityWarning,
+				ovnv1.OVNNorthdNBGlobalReadyDriftMessage,
+				strings.Join(instance.Status.NBGlobalOptionsDrift, " ")))
+		} else {
+			instance.Status.Conditions.MarkTrue(ovnv1.OVNNorthdNBGlobalReadyCondition, ovnv1.OVNNorthdNBGlobalReadyMessage)
+		}
+		return ctrl.Result{}, nil
+	}
+	instance.Status.NBGlobalOptionsCheckTime = &metav1.Time{Time: time.Now()}
+	instance.Status.NBGlobalOptions = applied
+	drift := ovnnorthd.GetNBGlobalDrift(options, applied)
+	instance.Status.NBGlobalOptionsDrift = nil
+	if len(drift) > 0 {
+		instance.Status.NBGlobalOptionsDrift = ovnnorthd.FormatOptions(drift)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			ovnv1.OVNNorthdNBGlobalReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityWarning,
+			ovnv1.OVNNorthdNBGlobalReadyDriftMessage,
+			strings.Join(instance.Status.NBGlobalOptionsDrift, " ")))
+		// the Job runs again once the succeeded one got deleted
+		err = job.DeleteJob(ctx, helper, ovnnorthd.NBGlobalJobName, instance.Namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		delete(instance.Status.Hash, ovnnorthd.NBGlobalHash)
+		return ctrl.Result{RequeueAfter: time.Duration(5) * time.Second}, nil
+	}
+	instance.Status.Conditions.MarkTrue(ovnv1.OVNNorthdNBGlobalReadyCondition, ovnv1.OVNNorthdNBGlobalReadyMessage)
+
 	return ctrl.Result{}, nil
 }
 
+// reconcileAutoscaling - sizes ovn-northd from the SB DB cells and the memory usage of the
+// active instance, collected every AutoscalingInterval once it is warmed up. The sizing is
+// deployed by the next reconcile in the auto mode.
+func (r *OVNNorthdReconciler) reconcileAutoscaling(
+	ctx context.Context,
+	instance *ovnv1.OVNNorthd,
+) {
+	Log := r.GetLogger(ctx)
+
+	mode := instance.Spec.Autoscaling.Mode
+	if mode == "" || mode == ovnv1.OVNNorthdAutoscalingDisabled {
+		instance.Status.Autoscaling = nil
+		return
+	}
+
+	status := instance.Status.Autoscaling
+	collected := false
+	if status == nil || time.Since(status.CollectionTime.Time) >= ovnnorthd.AutoscalingInterval {
+		// standby instances do not compute the logical flows, their memory usage is not relevant
+		pods, err := ovnnorthd.GetPods(ctx, r.Client, instance)
+		if err != nil {
+			Log.Info(fmt.Sprintf("Could not list the ovn-northd pods: %s", err))
+			return
+		}
+		for i := range pods {
+			if pods[i].Name != instance.Status.ActiveInstance {
+				continue
+			}
+			// the instance could still be loading the DBs after a restart, which is
+			// not its load
+			if ovnnorthd.IsWarmingUp(&pods[i], time.Now()) {
+				Log.Info(fmt.Sprintf("Not collecting the ovn-northd autoscaling inputs, %s just started", pods[i].Name))
+				break
+			}
+			sbCells, memoryUsage, err := ovnnorthd.CollectAutoscalingInputs(ctx, r.PodExecutor, &pods[i])
+			if err != nil {
+				// keep the previous sizing, the next reconcile collects again
+				Log.Info(fmt.Sprintf("Could not collect the ovn-northd autoscaling inputs: %s", err))
+				return
+			}
+			status = &ovnv1.OVNNorthdAutoscalingStatus{
+				SBCells:        sbCells,
+				MemoryUsage:    *memoryUsage,
+				Instance:       pods[i].Name,
+				CollectionTime: metav1.Now(),
+			}
+			collected = true
+		}
+	}
+	if status == nil {
+		return
+	}
+
+	// the bounds or the mode could have changed since the inputs got collected
+	instance.Status.Autoscaling = ovnnorthd.GetAutoscalingStatus(
+		instance, status.Instance, status.SBCells, status.MemoryUsage, status.CollectionTime)
+	if collected {
+		Log.Info(fmt.Sprintf("ovn-northd sized to %d thread(s) and requests %v, applied %t: %s",
+			instance.Status.Autoscaling.NThreads, instance.Status.Autoscaling.Requests,
+			instance.Status.Autoscaling.Applied, instance.Status.Autoscaling.Reason))
+	}
+}
+
+// createHashOfInputHashes - creates a hash of hashes which gets added to the resources which requires a restart
+// if any of the input resources change, like the DB endpoints or certs
+func (r *OVNNorthdReconciler) createHashOfInputHashes(
+	ctx context.Context,
+	instance *ovnv1.OVNNorthd,
+	nbEndpoint string,
+	sbEndpoint string,
+	envVars map[string]env.Setter,
+) (string, error) {
+	Log := r.GetLogger(ctx)
+
+	mergedMapVars := env.MergeEnvs([]corev1.EnvVar{
+		{Name: "NBEndpoint", Value: nbEndpoint},
+		{Name: "SBEndpoint", Value: sbEndpoint},
+	}, envVars)
+	hash, err := util.ObjectHash(mergedMapVars)
+	if err != nil {
+		return hash, err
+	}
+	if hashMap, changed := util.SetHash(instance.Status.Hash, common.InputHashName, hash); changed {
+		instance.Status.Hash = hashMap
+		Log.Info(fmt.Sprintf("Input maps hash %s - %s", common.InputHashName, hash))
+	}
+	return hash, nil
+}
+
+// generateServiceConfigMaps - create configmaps which hold the scripts of the service
+func (r *OVNNorthdReconciler) generateServiceConfigMaps(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *ovnv1.OVNNorthd,
+	envVars *map[string]env.Setter,
+) error {
+	cmLabels := labels.GetLabels(instance, labels.GetGroupLabel(ovnv1.ServiceNameOVNNorthd), map[string]string{})
+
+	templateParameters := make(map[string]interface{})
+	templateParameters["TLS"] = instance.Spec.TLS.Enabled()
+	templateParameters["OVNDB_CERT_PATH"] = ovn_common.OVNDbCertPath
+	templateParameters["OVNDB_KEY_PATH"] = ovn_common.OVNDbKeyPath
+	templateParameters["OVNDB_CACERT_PATH"] = ovn_common.OVNDbCaCertPath
+
+	cms := []util.Template{
+		// ScriptsConfigMap
+		{
+			Name:          fmt.Sprintf("%s-scripts", instance.Name),
+			Namespace:     instance.Namespace,
+			Type:          util.TemplateTypeScripts,
+			InstanceType:  instance.Kind,
+			Labels:        cmLabels,
+			ConfigOptions: templateParameters,
+		},
+	}
+	return configmap.EnsureConfigMaps(ctx, h, instance, cms, envVars)
+}
+
+// getEndpoint - connection string of the dbType DB following spec.endpointMode
+func getEndpoint(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *ovnv1.OVNNorthd,
+	dbType string,
+) (string, error) {
+	if instance.Spec.EndpointMode == ovnv1.OVNNorthdEndpointAnyMember {
+		cluster, err := ovnv1.GetDBClusterByType(ctx, h, instance.Namespace, map[string]string{}, dbType)
+		if err != nil {
+			return "", err
+		}
+		if cluster.Status.DBAddress == "" {
+			return "", fmt.Errorf("DB Service address not ready yet for %s", dbType)
+		}
+		return cluster.Status.DBAddress, nil
+	}
+	return getInternalEndpoint(ctx, h, instance, dbType)
+}
+
 func getInternalEndpoint(
 	ctx context.Context,
 	h *helper.Helper,
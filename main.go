@@ -44,6 +44,7 @@ import (
 
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/ovn-operator/controllers"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -95,6 +96,7 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "90840a60.openstack.org",
+		Cache:                  ovn_common.CacheOptions(),
 		WebhookServer: webhook.NewServer(
 			webhook.Options{
 				Port:    9443,
@@ -116,29 +118,41 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.OVNNorthdReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Kclient: kclient,
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Kclient:     kclient,
+		PodExecutor: ovn_common.NewPodExecutor(cfg, kclient),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OVNNorthd")
 		os.Exit(1)
 	}
 	if err = (&controllers.OVNDBClusterReconciler{
-		Client:  mgr.GetClient(),
-		Kclient: kclient,
-		Scheme:  mgr.GetScheme(),
+		Client:      mgr.GetClient(),
+		Kclient:     kclient,
+		Scheme:      mgr.GetScheme(),
+		PodExecutor: ovn_common.NewPodExecutor(cfg, kclient),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OVNDBCluster")
 		os.Exit(1)
 	}
 	if err = (&controllers.OVNControllerReconciler{
-		Client:  mgr.GetClient(),
-		Kclient: kclient,
-		Scheme:  mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Kclient:  kclient,
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("ovncontroller-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OVNController")
 		os.Exit(1)
 	}
+	if err = (&controllers.OVNDiagnosticsReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Kclient:     kclient,
+		PodExecutor: ovn_common.NewPodExecutor(cfg, kclient),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OVNDiagnostics")
+		os.Exit(1)
+	}
 
 	// Acquire environmental defaults and initialize operator defaults with them
 	ovnv1.SetupDefaults()
@@ -0,0 +1,45 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+)
+
+// CacheOptions - cache options of the manager. Only the pods of the OVN services get
+// cached and watched instead of all the pods of the cluster.
+func CacheOptions() cache.Options {
+	services, err := labels.NewRequirement(common.AppSelector, selection.In, []string{
+		ovnv1.ServiceNameOVNController,
+		ovnv1.ServiceNameOVS,
+		ovnv1.ServiceNameOVNNorthd,
+		ovnv1.ServiceNameNB,
+		ovnv1.ServiceNameSB,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Pod{}: {Label: labels.NewSelector().Add(*services)},
+		},
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+)
+
+// UpdateReadyCondition - sets the Ready condition of conditions based on the sub conditions,
+// leaving out the informational ones in ignored. They get reported but never flip Ready.
+func UpdateReadyCondition(conditions *condition.Conditions, ignored ...condition.Type) {
+	informational := condition.Conditions{}
+	for _, t := range ignored {
+		if c := conditions.Get(t); c != nil {
+			informational = append(informational, *c)
+			conditions.Remove(t)
+		}
+	}
+
+	if conditions.AllSubConditionIsTrue() {
+		conditions.MarkTrue(condition.ReadyCondition, condition.ReadyMessage)
+	} else {
+		// something is not ready so reset the Ready condition
+		conditions.MarkUnknown(condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+		// and recalculate it based on the state of the rest of the conditions
+		conditions.Set(conditions.Mirror(condition.ReadyCondition))
+	}
+
+	for i := range informational {
+		conditions.Set(&informational[i])
+	}
+}
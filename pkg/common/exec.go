@@ -0,0 +1,93 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecTimeout - time a command run through the PodExecutor may take, it gets cancelled
+// after it. It keeps a hung container or API server from blocking a reconcile.
+const ExecTimeout = 30 * time.Second
+
+// PodExecutor - runs commands in the containers of the OVN pods, e.g. ovn-appctl. The
+// functional tests replace it as envtest does not run pods.
+type PodExecutor interface {
+	// Exec - runs command in container of pod and returns its stdout
+	Exec(ctx context.Context, pod *corev1.Pod, container string, command []string) (string, error)
+}
+
+type podExecutor struct {
+	config  *rest.Config
+	kclient kubernetes.Interface
+}
+
+// NewPodExecutor - PodExecutor using the pods/exec subresource
+func NewPodExecutor(config *rest.Config, kclient kubernetes.Interface) PodExecutor {
+	return &podExecutor{config: config, kclient: kclient}
+}
+
+// Exec - runs command in container of pod, which has to be running. The command
+// is cancelled after ExecTimeout. The error includes the stderr of the command.
+func (e *podExecutor) Exec(
+	ctx context.Context,
+	pod *corev1.Pod,
+	container string,
+	command []string,
+) (string, error) {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return "", fmt.Errorf("pod %s is not running", pod.Name)
+	}
+
+	req := e.kclient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ExecTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return stdout.String(), fmt.Errorf("%s in pod %s failed: %w: %s",
+			strings.Join(command, " "), pod.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,133 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+)
+
+// OVNVersionCheckInterval - interval at which the OVN version Job is checked until it completed,
+// its pods are not watched
+const OVNVersionCheckInterval = 5 * time.Second
+
+// OVNVersionJobName - name of the Job finding the OVN version of image for owner
+func OVNVersionJobName(owner client.Object, image string) (string, error) {
+	hash, err := util.ObjectHash(image)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-ovn-version-%.8s", owner.GetName(), hash), nil
+}
+
+// OVNVersionJob - Job printing the OVN version of image to its termination message
+func OVNVersionJob(owner client.Object, name string, image string, serviceAccount string) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](3),
+			// Jobs of images replaced before their version got read are not left behind
+			TTLSecondsAfterFinished: ptr.To[int32](3600),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: serviceAccount,
+					Containers: []corev1.Container{
+						{
+							Name:  "ovn-version",
+							Image: image,
+							Command: []string{
+								"/bin/bash", "-c",
+								"ovn-appctl --version | awk 'NR==1 {print $NF}' > /dev/termination-log",
+							},
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// GetOVNVersion - OVN version of image, known when it is the image of known. Otherwise it is
+// found by a Job running the image, nil is returned until it completed. The pods of the Job
+// are read from the API server, the cache of the manager only holds the ones of the services.
+func GetOVNVersion(
+	ctx context.Context,
+	h *helper.Helper,
+	owner client.Object,
+	image string,
+	serviceAccount string,
+	known *ovnv1.OVNImageVersion,
+) (*ovnv1.OVNImageVersion, error) {
+	if known != nil && known.Image == image {
+		return known, nil
+	}
+
+	name, err := OVNVersionJobName(owner, image)
+	if err != nil {
+		return nil, err
+	}
+	job := &batchv1.Job{}
+	err = h.GetClient().Get(ctx, types.NamespacedName{Namespace: owner.GetNamespace(), Name: name}, job)
+	if k8s_errors.IsNotFound(err) {
+		job = OVNVersionJob(owner, name, image, serviceAccount)
+		if err := controllerutil.SetControllerReference(owner, job, h.GetScheme()); err != nil {
+			return nil, err
+		}
+		return nil, h.GetClient().Create(ctx, job)
+	} else if err != nil {
+		return nil, err
+	}
+
+	pods, err := h.GetKClient().CoreV1().Pods(owner.GetNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: "job-name=" + name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil || terminated.ExitCode != 0 || strings.TrimSpace(terminated.Message) == "" {
+				continue
+			}
+			// the version is kept by the caller, the Job is not needed anymore
+			err := h.GetClient().Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !k8s_errors.IsNotFound(err) {
+				return nil, err
+			}
+			return &ovnv1.OVNImageVersion{Image: image, Version: strings.TrimSpace(terminated.Message)}, nil
+		}
+	}
+
+	return nil, nil
+}
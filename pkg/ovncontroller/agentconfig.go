@@ -0,0 +1,81 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovncontroller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AgentConfigGenerationKey - key of the agent ConfigMap holding the generation to apply,
+	// the config itself is stored under <generation>.env
+	AgentConfigGenerationKey = "generation"
+)
+
+// AgentConfigMapName - name of the ConfigMap applied by the ovn-config-agent containers
+func AgentConfigMapName(instance *ovnv1.OVNController) string {
+	return instance.Name + "-agent-config"
+}
+
+// AgentConfig - renders the external-ids and bridge mappings applied by the
+// ovn-config-agent containers as <name>=<value> lines. Returns the generation
+// of the config, a hash of its content, together with the config.
+func AgentConfig(
+	instance *ovnv1.OVNController,
+	sbCluster *ovnv1.OVNDBCluster,
+) (string, string, error) {
+	internalEndpoint, err := sbCluster.GetInternalEndpoint()
+	if err != nil {
+		return "", "", err
+	}
+
+	envVars := map[string]env.Setter{}
+	envVars["OVNBridge"] = env.SetValue(instance.Spec.ExternalIDS.OvnBridge)
+	envVars["OVNRemote"] = env.SetValue(internalEndpoint)
+	envVars["OVNEncapType"] = env.SetValue(instance.Spec.ExternalIDS.OvnEncapType)
+	envVars["OVNAvailabilityZones"] = env.SetValue(strings.Join(instance.Spec.ExternalIDS.OvnAvailabilityZones, ":"))
+	envVars["EnableChassisAsGateway"] = env.SetValue(fmt.Sprintf("%t", *instance.Spec.ExternalIDS.EnableChassisAsGateway))
+	envVars["OVNExternalIDs"] = env.SetValue(getExternalIDs(instance))
+	envVars["PhysicalNetworks"] = env.SetValue(getPhysicalNetworks(instance))
+	envVars["OVSBridges"] = env.SetValue(getBridges(instance))
+	for name, value := range getDPDKEnvVars(instance) {
+		envVars[name] = value
+	}
+	if restart := instance.Spec.GracefulRestart; restart != nil {
+		envVars["OVNGracefulRestart"] = env.SetValue("true")
+		envVars["OVNWaitBeforeClearMin"] = env.SetValue(fmt.Sprintf("%d", restart.MinWaitBeforeClear))
+		envVars["OVNWaitBeforeClearMax"] = env.SetValue(fmt.Sprintf("%d", restart.MaxWaitBeforeClear))
+	}
+
+	lines := []string{}
+	for _, envVar := range env.MergeEnvs([]corev1.EnvVar{}, envVars) {
+		lines = append(lines, envVar.Name+"="+envVar.Value)
+	}
+	sort.Strings(lines)
+	config := strings.Join(lines, "\n") + "\n"
+
+	generation, err := util.ObjectHash(config)
+	if err != nil {
+		return "", "", err
+	}
+
+	return generation, config, nil
+}
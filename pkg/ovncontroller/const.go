@@ -1 +1,46 @@
 package ovncontroller
+
+const (
+	// AgentConfigDir - directory the agent ConfigMap is mounted in the ovn-config-agent container
+	AgentConfigDir = "/var/lib/ovn-config-agent"
+
+	// ConfigGenerationAnnotation - generation of the agent config last applied on the node
+	ConfigGenerationAnnotation = "ovn.openstack.org/config-generation"
+
+	// ConfigErrorAnnotation - error of the last failed attempt to apply the agent config on the node
+	ConfigErrorAnnotation = "ovn.openstack.org/config-error"
+
+	// BridgeDriftAnnotation - ';' separated differences between spec.bridges and OVS
+	// found by the last configuration run of the node
+	BridgeDriftAnnotation = "ovn.openstack.org/bridge-drift"
+
+	// HardwareOffloadAnnotation - other_config:hw-offload of ovs-vswitchd on the node
+	HardwareOffloadAnnotation = "ovn.openstack.org/hw-offload"
+
+	// OffloadedFlowsAnnotation - number of datapath flows offloaded to the NICs of the node
+	OffloadedFlowsAnnotation = "ovn.openstack.org/offloaded-flows"
+
+	// TemplateHashLabel - pod label with the hash of the pod template of the DaemonSet,
+	// used to find the outdated pods during an image rollout
+	TemplateHashLabel = "ovn.openstack.org/template-hash"
+
+	// FlowRestoreAnnotation - outcome of the last restore of the flows saved when ovs-vswitchd
+	// stopped, as "result=<result> saved=<flows> restored=<flows> time=<RFC 3339 time>"
+	FlowRestoreAnnotation = "ovn.openstack.org/flow-restore"
+
+	// OVNVersionAnnotation - version reported by the ovn-controller running on the node
+	OVNVersionAnnotation = "ovn.openstack.org/ovn-version"
+
+	// HealthAnnotation - outcome of the last readiness probe of ovn-controller, "ok" or the failure
+	HealthAnnotation = "ovn.openstack.org/health"
+
+	// RecomputeTimeAnnotation - longest flow recompute of ovn-controller measured on the node, in milliseconds
+	RecomputeTimeAnnotation = "ovn.openstack.org/recompute-time"
+
+	// WaitBeforeClearAnnotation - ovn-ofctrl-wait-before-clear set on the node, in milliseconds
+	WaitBeforeClearAnnotation = "ovn.openstack.org/wait-before-clear"
+
+	// RestartDowntimeAnnotation - milliseconds between the graceful exit of the previous
+	// ovn-controller and this one being ready, set on the ovn-controller pod
+	RestartDowntimeAnnotation = "ovn.openstack.org/restart-downtime"
+)
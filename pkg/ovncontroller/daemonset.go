@@ -14,6 +14,8 @@ package ovncontroller
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
@@ -26,12 +28,28 @@ import (
 	"k8s.io/utils/ptr"
 )
 
+const (
+	// probeCommandTimeout - seconds each command of the ovn-controller probe may take
+	probeCommandTimeout = 5
+	// probeReadinessCommands - commands the readiness probe of ovn-controller runs at most:
+	// connection-status, ovs-vsctl, ovs-ofctl, version and three pod annotations
+	probeReadinessCommands = 7
+	// livenessCommandTimeout - seconds the main loop of ovn-controller has to answer the liveness probe
+	livenessCommandTimeout = 10
+	// livenessFailureWindow - seconds ovn-controller may keep failing the liveness probe before
+	// it is restarted, longer than a full recompute of a big deployment
+	livenessFailureWindow = 300
+	// startupFailureWindow - seconds a starting ovn-controller has to answer the liveness probe,
+	// it downloads the SB DB and recomputes all the flows first
+	startupFailureWindow = 600
+)
+
 func CreateOVNDaemonSet(
 	instance *ovnv1.OVNController,
 	configHash string,
 	labels map[string]string,
 ) *appsv1.DaemonSet {
-	volumes := GetOVNControllerVolumes(instance.Name, instance.Namespace)
+	volumes := GetOVNControllerVolumes(instance.Name, instance.Namespace, instance.Status.HostStateDir)
 	mounts := GetOVNControllerVolumeMounts()
 
 	cmd := []string{
@@ -67,15 +85,62 @@ func CreateOVNDaemonSet(
 
 	envVars := map[string]env.Setter{}
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
+	// the readiness probe reports the health of ovn-controller as an annotation of this pod
+	envVars["OVNPodName"] = env.DownwardAPI("metadata.name")
+
+	//
+	// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
+	//
+	// the liveness probe checks the ovn-controller process is running and its main loop
+	// answers. A full recompute keeps the main loop busy for minutes in a big deployment,
+	// so only livenessFailureWindow seconds without any answer get ovn-controller restarted
+	livenessProbe := &corev1.Probe{
+		TimeoutSeconds:   livenessCommandTimeout + 5,
+		PeriodSeconds:    30,
+		FailureThreshold: livenessFailureWindow / 30,
+	}
+	// the startup probe runs the liveness one until it first succeeded
+	startupProbe := &corev1.Probe{
+		TimeoutSeconds:   livenessCommandTimeout + 5,
+		PeriodSeconds:    10,
+		FailureThreshold: startupFailureWindow / 10,
+	}
+	// the readiness probe runs its commands one after the other, each of them
+	// bounded to probeCommandTimeout seconds
+	readinessProbe := &corev1.Probe{
+		TimeoutSeconds:      probeCommandTimeout*probeReadinessCommands + 5,
+		PeriodSeconds:       10,
+		InitialDelaySeconds: 5,
+	}
+	envVars["ProbeTimeout"] = env.SetValue(strconv.Itoa(probeCommandTimeout))
+	envVars["AnnotateTimeout"] = env.SetValue(strconv.Itoa(probeCommandTimeout))
+	envVars["LivenessTimeout"] = env.SetValue(strconv.Itoa(livenessCommandTimeout))
+	livenessProbe.Exec = &corev1.ExecAction{
+		Command: []string{"/usr/local/bin/container-scripts/ovn-controller-probe.sh", "liveness"},
+	}
+	startupProbe.Exec = livenessProbe.Exec
+	readinessProbe.Exec = &corev1.ExecAction{
+		Command: []string{"/usr/local/bin/container-scripts/ovn-controller-probe.sh", "readiness"},
+	}
+
+	// ovn-ctl stop_controller makes ovn-controller clear its flows on exit, with a graceful restart
+	// they are kept until the next ovn-controller replaces them
+	preStop := []string{"/usr/share/ovn/scripts/ovn-ctl", "stop_controller"}
+	if instance.Spec.GracefulRestart != nil {
+		preStop = []string{"/usr/local/bin/container-scripts/stop-ovn-controller.sh"}
+	}
 
 	containers := []corev1.Container{
 		{
-			Name:    "ovn-controller",
-			Command: cmd,
+			Name:           "ovn-controller",
+			Command:        cmd,
+			LivenessProbe:  livenessProbe,
+			ReadinessProbe: readinessProbe,
+			StartupProbe:   startupProbe,
 			Lifecycle: &corev1.Lifecycle{
 				PreStop: &corev1.LifecycleHandler{
 					Exec: &corev1.ExecAction{
-						Command: []string{"/usr/share/ovn/scripts/ovn-ctl", "stop_controller"},
+						Command: preStop,
 					},
 				},
 			},
@@ -96,6 +161,28 @@ func CreateOVNDaemonSet(
 		},
 	}
 
+	// applies the agent ConfigMap to OVS and keeps correcting any drift, only writing the
+	// values which differ. It runs next to ovn-controller rather than in the OVS pods: its
+	// scripts come with the OVN image, and an agent in the OVS DaemonSet would tie the OVS
+	// pods to that image, restarting ovs-vswitchd and so the datapath on every OVN update.
+	agentEnvVars := map[string]env.Setter{}
+	agentEnvVars["CONFIG_DIR"] = env.SetValue(AgentConfigDir)
+	agentEnvVars["OVNHostName"] = env.DownwardAPI("spec.nodeName")
+	agentEnvVars["OVNPodName"] = env.DownwardAPI("metadata.name")
+	volumes = append(volumes, GetAgentConfigVolume(instance.Name))
+	containers = append(containers, corev1.Container{
+		Name:    "ovn-config-agent",
+		Command: []string{"/usr/local/bin/container-scripts/start-config-agent.sh"},
+		Image:   instance.Spec.OvnContainerImage,
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:  &runAsUser,
+			Privileged: &privileged,
+		},
+		Env:                      env.MergeEnvs([]corev1.EnvVar{}, agentEnvVars),
+		VolumeMounts:             GetAgentVolumeMounts(),
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	})
+
 	daemonset := &appsv1.DaemonSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ovnv1.ServiceNameOVNController,
@@ -130,6 +217,7 @@ func CreateOVSDaemonSet(
 	configHash string,
 	labels map[string]string,
 	annotations map[string]string,
+	networkResources corev1.ResourceList,
 ) *appsv1.DaemonSet {
 	//
 	// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
@@ -167,6 +255,74 @@ func CreateOVSDaemonSet(
 	envVars := map[string]env.Setter{}
 	envVars["CONFIG_HASH"] = env.SetValue(configHash)
 
+	// device plugin resources (e.g. SR-IOV VFs) backing the network attachments
+	vswitchdResources := *instance.Spec.Resources.DeepCopy()
+	for name, quantity := range networkResources {
+		if vswitchdResources.Requests == nil {
+			vswitchdResources.Requests = corev1.ResourceList{}
+		}
+		if vswitchdResources.Limits == nil {
+			vswitchdResources.Limits = corev1.ResourceList{}
+		}
+		vswitchdResources.Requests[name] = quantity
+		vswitchdResources.Limits[name] = quantity
+	}
+
+	vswitchdEnvVars := map[string]env.Setter{}
+	vswitchdEnvVars["CONFIG_HASH"] = env.SetValue(configHash)
+	// the flow restore outcome and the offload state are reported as annotations on this pod
+	vswitchdEnvVars["OVSPodName"] = env.DownwardAPI("metadata.name")
+	vswitchdCapabilities := []corev1.Capability{"NET_ADMIN", "SYS_ADMIN", "SYS_NICE"}
+	vswitchdMounts := GetVswitchdVolumeMounts()
+	volumes := GetOVSVolumes(instance.Name, instance.Namespace, instance.Status.HostStateDir)
+
+	if dpdk := instance.Spec.DPDK; dpdk != nil {
+		// DPDK settings need to be in place before ovs-vswitchd starts
+		for name, value := range getDPDKEnvVars(instance) {
+			vswitchdEnvVars[name] = value
+		}
+		vswitchdCapabilities = append(vswitchdCapabilities, "IPC_LOCK")
+		volumes = append(volumes, GetDPDKVolumes(dpdk.HugepageSize)...)
+		vswitchdMounts = append(vswitchdMounts, GetDPDKVolumeMounts()...)
+
+		hugepages := corev1.ResourceName(corev1.ResourceHugePagesPrefix + dpdk.HugepageSize)
+		if vswitchdResources.Requests == nil {
+			vswitchdResources.Requests = corev1.ResourceList{}
+		}
+		if vswitchdResources.Limits == nil {
+			vswitchdResources.Limits = corev1.ResourceList{}
+		}
+		vswitchdResources.Requests[hugepages] = dpdk.Hugepages
+		vswitchdResources.Limits[hugepages] = dpdk.Hugepages
+	}
+
+	var switchdevInitContainers []corev1.Container
+	if offload := instance.Spec.HardwareOffload; offload != nil {
+		vswitchdEnvVars["OVSHWOffload"] = env.SetValue("true")
+		vswitchdEnvVars["OVSTCPolicy"] = env.SetValue(offload.TCPolicy)
+
+		if len(offload.PhysicalFunctions) > 0 {
+			switchdevEnvVars := map[string]env.Setter{}
+			switchdevEnvVars["OVSSwitchdevPFs"] = env.SetValue(strings.Join(offload.PhysicalFunctions, " "))
+			volumes = append(volumes, GetHostProcVolume())
+			switchdevInitContainers = append(switchdevInitContainers, corev1.Container{
+				Name:    "ovs-switchdev-init",
+				Command: []string{"/usr/local/bin/container-scripts/init-switchdev.sh"},
+				Image:   instance.Spec.OvsContainerImage,
+				SecurityContext: &corev1.SecurityContext{
+					Capabilities: &corev1.Capabilities{
+						Add:  []corev1.Capability{"NET_ADMIN", "SYS_ADMIN"},
+						Drop: []corev1.Capability{},
+					},
+					RunAsUser:  &runAsUser,
+					Privileged: &privileged,
+				},
+				Env:          env.MergeEnvs([]corev1.EnvVar{}, switchdevEnvVars),
+				VolumeMounts: GetSwitchdevVolumeMounts(),
+			})
+		}
+	}
+
 	initContainers := []corev1.Container{
 		{
 			Name:    "ovsdb-server-init",
@@ -226,16 +382,16 @@ func CreateOVSDaemonSet(
 			Image: instance.Spec.OvsContainerImage,
 			SecurityContext: &corev1.SecurityContext{
 				Capabilities: &corev1.Capabilities{
-					Add:  []corev1.Capability{"NET_ADMIN", "SYS_ADMIN", "SYS_NICE"},
+					Add:  vswitchdCapabilities,
 					Drop: []corev1.Capability{},
 				},
 				RunAsUser:  &runAsUser,
 				Privileged: &privileged,
 			},
-			Env:          env.MergeEnvs([]corev1.EnvVar{}, envVars),
-			VolumeMounts: GetVswitchdVolumeMounts(),
+			Env:          env.MergeEnvs([]corev1.EnvVar{}, vswitchdEnvVars),
+			VolumeMounts: vswitchdMounts,
 			// TODO: consider the fact that resources are now double booked
-			Resources:                instance.Spec.Resources,
+			Resources:                vswitchdResources,
 			LivenessProbe:            ovsVswitchdLivenessProbe,
 			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 		},
@@ -256,9 +412,9 @@ func CreateOVSDaemonSet(
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: instance.RbacResourceName(),
-					InitContainers:     initContainers,
+					InitContainers:     append(switchdevInitContainers, initContainers...),
 					Containers:         containers,
-					Volumes:            GetOVSVolumes(instance.Name, instance.Namespace),
+					Volumes:            volumes,
 				},
 			},
 		},
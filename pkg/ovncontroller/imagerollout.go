@@ -0,0 +1,275 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovncontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SetTemplateHash - labels the pod template of the DaemonSet with its hash and returns it. Only
+// used with spec.imageRollout, the label changes the template and so replaces all the pods.
+func SetTemplateHash(daemonset *appsv1.DaemonSet) (string, error) {
+	hash, err := util.ObjectHash(daemonset.Spec.Template)
+	if err != nil {
+		return "", err
+	}
+	daemonset.Spec.Template.Labels = util.MergeStringMaps(
+		daemonset.Spec.Template.Labels,
+		map[string]string{TemplateHashLabel: hash},
+	)
+	return hash, nil
+}
+
+// EnsureUpdateStrategy - sets the update strategy of an existing DaemonSet, OnDelete when
+// spec.imageRollout is set. It has to run before the pod template gets patched, otherwise
+// the DaemonSet controller would already have started a rolling update.
+func EnsureUpdateStrategy(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNController,
+	name string,
+) error {
+	strategy := appsv1.RollingUpdateDaemonSetStrategyType
+	if instance.Spec.ImageRollout != nil {
+		strategy = appsv1.OnDeleteDaemonSetStrategyType
+	}
+
+	daemonset := &appsv1.DaemonSet{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: name}, daemonset)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if daemonset.Spec.UpdateStrategy.Type == strategy {
+		return nil
+	}
+
+	patch := client.MergeFrom(daemonset.DeepCopy())
+	daemonset.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: strategy}
+	return k8sClient.Patch(ctx, daemonset, patch)
+}
+
+// RolloutImages - replaces the pods of the nodes running an outdated pod template following
+// spec.imageRollout. templateHashes holds the current template hash of each service. The pods
+// of the canary nodes are replaced first, then the ones of the remaining nodes in waves. A wave
+// only starts once the nodes of the previous one are healthy, see getUnhealthyReason. The
+// progress is recorded in Status.ImageRollout, it relies on Status.Nodes being up to date.
+func RolloutImages(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNController,
+	templateHashes map[string]string,
+) error {
+	spec := instance.Spec.ImageRollout
+	revision, err := util.ObjectHash(templateHashes)
+	if err != nil {
+		return err
+	}
+
+	nodePods := map[string][]corev1.Pod{}
+	for service := range templateHashes {
+		pods, err := getServicePods(ctx, k8sClient, instance, service)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods.Items {
+			if pod.Spec.NodeName != "" {
+				nodePods[pod.Spec.NodeName] = append(nodePods[pod.Spec.NodeName], pod)
+			}
+		}
+	}
+
+	nodeList := &corev1.NodeList{}
+	if err := k8sClient.List(ctx, nodeList); err != nil {
+		return err
+	}
+	clusterNodes := map[string]bool{}
+	canaryNodes := map[string]bool{}
+	canarySelector := labels.SelectorFromSet(spec.CanaryNodeSelector)
+	for _, node := range nodeList.Items {
+		clusterNodes[node.Name] = true
+		canaryNodes[node.Name] = len(spec.CanaryNodeSelector) > 0 && canarySelector.Matches(labels.Set(node.Labels))
+	}
+
+	isOutdated := func(pod corev1.Pod) bool {
+		return pod.Labels[TemplateHashLabel] != templateHashes[pod.Labels[common.AppSelector]]
+	}
+	nodes := []string{}
+	outdatedNodes := []string{}
+	for node, pods := range nodePods {
+		nodes = append(nodes, node)
+		for _, pod := range pods {
+			if isOutdated(pod) {
+				outdatedNodes = append(outdatedNodes, node)
+				break
+			}
+		}
+	}
+	sort.Strings(nodes)
+	sort.Strings(outdatedNodes)
+
+	rollout := instance.Status.ImageRollout
+	if rollout == nil || rollout.Revision != revision {
+		rollout = &ovnv1.OVNControllerImageRolloutStatus{
+			Revision: revision,
+			Phase:    ovnv1.ImageRolloutPhaseCanary,
+		}
+	}
+	instance.Status.ImageRollout = rollout
+	rollout.UpdatedNodes = int32(len(nodes) - len(outdatedNodes))
+	rollout.TotalNodes = int32(len(nodes))
+	now := metav1.Now()
+
+	// wait for the nodes of the current wave, nodes which left the cluster are not waited for
+	if len(rollout.WaveNodes) > 0 {
+		unhealthyNodes := map[string]string{}
+		for _, node := range rollout.WaveNodes {
+			if !clusterNodes[node] {
+				continue
+			}
+			if reason := getUnhealthyReason(instance, nodePods[node], len(templateHashes), isOutdated, now); reason != "" {
+				unhealthyNodes[node] = reason
+			}
+		}
+		rollout.UnhealthyNodes = unhealthyNodes
+		if len(unhealthyNodes) > 0 {
+			deadline := time.Duration(spec.ProgressDeadlineSeconds) * time.Second
+			rollout.Paused = rollout.WaveStartTime != nil && now.Sub(rollout.WaveStartTime.Time) > deadline
+			return nil
+		}
+		rollout.WaveNodes = nil
+		rollout.WaveStartTime = nil
+		rollout.Paused = false
+		if rollout.Phase == ovnv1.ImageRolloutPhaseCanary {
+			rollout.Phase = ovnv1.ImageRolloutPhaseWaves
+		}
+	}
+
+	if len(outdatedNodes) == 0 {
+		rollout.Phase = ovnv1.ImageRolloutPhaseComplete
+		return nil
+	}
+
+	wave := []string{}
+	if rollout.Phase == ovnv1.ImageRolloutPhaseCanary {
+		for _, node := range outdatedNodes {
+			if canaryNodes[node] {
+				wave = append(wave, node)
+			}
+		}
+		if len(wave) == 0 {
+			rollout.Phase = ovnv1.ImageRolloutPhaseWaves
+		}
+	}
+	if rollout.Phase != ovnv1.ImageRolloutPhaseCanary {
+		rollout.Phase = ovnv1.ImageRolloutPhaseWaves
+		waveSize, err := intstr.GetScaledValueFromIntOrPercent(spec.WaveSize, len(nodes), true)
+		if err != nil {
+			return err
+		}
+		wave = outdatedNodes[:min(max(waveSize, 1), len(outdatedNodes))]
+	}
+
+	for _, node := range wave {
+		for _, pod := range nodePods[node] {
+			if !isOutdated(pod) || pod.DeletionTimestamp != nil {
+				continue
+			}
+			pod := pod
+			if err := k8sClient.Delete(ctx, &pod); err != nil && !k8s_errors.IsNotFound(err) {
+				return fmt.Errorf("error deleting pod %s of node %s: %w", pod.Name, node, err)
+			}
+		}
+	}
+	rollout.WaveNodes = wave
+	rollout.WaveStartTime = &now
+	rollout.UnhealthyNodes = nil
+
+	return nil
+}
+
+// getUnhealthyReason - returns why a node of a wave is not healthy yet, or an empty string once
+// all its pods run the current template and are ready since spec.imageRollout.minHealthySeconds,
+// without the node reporting a config error or a failed flow restore
+func getUnhealthyReason(
+	instance *ovnv1.OVNController,
+	pods []corev1.Pod,
+	numServices int,
+	isOutdated func(corev1.Pod) bool,
+	now metav1.Time,
+) string {
+	minHealthy := time.Duration(ptr.Deref(instance.Spec.ImageRollout.MinHealthySeconds, 0)) * time.Second
+
+	current := 0
+	for _, pod := range pods {
+		if isOutdated(pod) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		current++
+		ready := false
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type != corev1.PodReady || cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			ready = true
+			if now.Sub(cond.LastTransitionTime.Time) < minHealthy {
+				return fmt.Sprintf("pod %s ready for less than %s", pod.Name, minHealthy)
+			}
+		}
+		if !ready {
+			return fmt.Sprintf("pod %s not ready", pod.Name)
+		}
+	}
+	if current < numServices {
+		return "pods not replaced yet"
+	}
+
+	nodeStatus := instance.Status.Nodes[pods[0].Spec.NodeName]
+	if nodeStatus.ConfigError != "" {
+		return "config error: " + nodeStatus.ConfigError
+	}
+	if restore := nodeStatus.FlowRestore; restore != nil && restore.Result == ovnv1.FlowRestoreFailed {
+		return "flow restore failed"
+	}
+
+	return ""
+}
+
+// GetUnhealthyNodes - sorted, comma separated, nodes of the current wave which are not healthy
+func GetUnhealthyNodes(rollout *ovnv1.OVNControllerImageRolloutStatus) string {
+	unhealthy := []string{}
+	for node, reason := range rollout.UnhealthyNodes {
+		unhealthy = append(unhealthy, fmt.Sprintf("%s (%s)", node, reason))
+	}
+	sort.Strings(unhealthy)
+	return strings.Join(unhealthy, ", ")
+}
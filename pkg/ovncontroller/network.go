@@ -14,83 +14,274 @@ package ovncontroller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 
 	netattdefv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
 )
 
-// CreateOrUpdateAdditionalNetworks - create or update network attachment definitions based on the provided mappings
+// resourceNameAnnotation - device plugin resource backing a NetworkAttachmentDefinition
+const resourceNameAnnotation = "k8s.v1.cni.cncf.io/resourceName"
+
+// AdditionalNetworks - NetworkAttachmentDefinitions providing the physical network interfaces of the OVS pods
+type AdditionalNetworks struct {
+	// Names - NetworkAttachmentDefinitions to attach to the OVS pods
+	Names []string
+	// Unmanaged - pre-existing NetworkAttachmentDefinitions not owned by the OVNController, used as they are
+	Unmanaged []string
+	// Resources - device plugin resources the OVS pods have to request for the attachments
+	Resources corev1.ResourceList
+}
+
+type additionalNetwork struct {
+	config       string
+	resourceName string
+}
+
+// getAdditionalNetworks - returns the NetworkAttachmentDefinitions moving host interfaces into the
+// OVS pods, keyed by the NetworkAttachmentDefinition name. NicMappings interfaces are named after
+// their physical network and spec.bridges uplink interfaces, DPDK devices excepted, keep their host name.
+func getAdditionalNetworks(
+	instance *ovnv1.OVNController,
+) (map[string]additionalNetwork, error) {
+	networks := map[string]additionalNetwork{}
+	hostDevice := `{"cniVersion": "0.3.1", "name": "%s", "type": "host-device", "device": "%s"}`
+
+	for physNet, interfaceName := range instance.Spec.NicMappings {
+		var cniConfig map[string]interface{}
+		network := additionalNetwork{}
+
+		cfg := instance.Spec.NicMappingsConfig[physNet]
+		switch cfg.Type {
+		case "", ovnv1.NicMappingTypeHostDevice:
+			network.config = fmt.Sprintf(hostDevice, physNet, interfaceName)
+		case ovnv1.NicMappingTypeBond:
+			links := []map[string]string{}
+			for _, member := range cfg.Members {
+				links = append(links, map[string]string{"name": member})
+			}
+			cniConfig = map[string]interface{}{
+				"mode":             cfg.BondMode,
+				"failOverMac":      1,
+				"linksInContainer": false,
+				"miimon":           "100",
+				"links":            links,
+			}
+		case ovnv1.NicMappingTypeMacvlan:
+			cniConfig = map[string]interface{}{
+				"master": interfaceName,
+				"mode":   "passthru",
+			}
+		case ovnv1.NicMappingTypeSRIOV:
+			// OVS needs to see all the traffic of the VF
+			cniConfig = map[string]interface{}{
+				"spoofchk": "off",
+				"trust":    "on",
+			}
+			network.resourceName = cfg.ResourceName
+		default:
+			return nil, fmt.Errorf("unsupported attachment type %s for physical network %s", cfg.Type, physNet)
+		}
+
+		if cniConfig != nil {
+			cniConfig["cniVersion"] = "0.3.1"
+			cniConfig["name"] = physNet
+			cniConfig["type"] = cfg.Type
+			config, err := json.Marshal(cniConfig)
+			if err != nil {
+				return nil, err
+			}
+			network.config = string(config)
+		}
+		networks[physNet] = network
+	}
+	for _, br := range instance.Spec.Bridges {
+		if br.Uplink == nil {
+			continue
+		}
+		for _, interfaceName := range br.Uplink.Interfaces {
+			// DPDK devices are driven through VFIO, not moved into the pods
+			if instance.Spec.DPDK != nil && instance.Spec.DPDK.Devices[interfaceName] != "" {
+				continue
+			}
+			networks[interfaceName] = additionalNetwork{
+				config: fmt.Sprintf(hostDevice, interfaceName, interfaceName),
+			}
+		}
+	}
+
+	return networks, nil
+}
+
+// validateNetworkConfig - checks the CNI config generated for NetworkAttachmentDefinition name
+// carries what its plugin needs before it gets created
+func validateNetworkConfig(name string, config string) error {
+	cniConfig := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(config), &cniConfig); err != nil {
+		return fmt.Errorf("invalid CNI config for NetworkAttachmentDefinition %s: %w", name, err)
+	}
+
+	required := []string{"cniVersion", "name", "type"}
+	switch cniConfig["type"] {
+	case ovnv1.NicMappingTypeHostDevice:
+		required = append(required, "device")
+	case ovnv1.NicMappingTypeBond:
+		required = append(required, "mode", "links")
+	case ovnv1.NicMappingTypeMacvlan:
+		required = append(required, "master", "mode")
+	}
+	for _, key := range required {
+		if value, ok := cniConfig[key]; !ok || value == "" {
+			return fmt.Errorf("invalid CNI config for NetworkAttachmentDefinition %s: missing %s", name, key)
+		}
+	}
+	if cniConfig["name"] != name {
+		return fmt.Errorf("invalid CNI config for NetworkAttachmentDefinition %s: name is %v", name, cniConfig["name"])
+	}
+	if links, ok := cniConfig["links"].([]interface{}); ok && len(links) == 0 {
+		return fmt.Errorf("invalid CNI config for NetworkAttachmentDefinition %s: no bond links", name)
+	}
+
+	return nil
+}
+
+// CreateOrUpdateAdditionalNetworks - create or update network attachment definitions based on the provided mappings.
+// Pre-existing NetworkAttachmentDefinitions not owned by the instance are used without modification.
 func CreateOrUpdateAdditionalNetworks(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *ovnv1.OVNController,
 	labels map[string]string,
-) ([]string, error) {
+) (*AdditionalNetworks, error) {
 
 	var nad *netattdefv1.NetworkAttachmentDefinition
-	var networkAttachments []string
+	additionalNetworks := &AdditionalNetworks{
+		Resources: corev1.ResourceList{},
+	}
 
-	for physNet, interfaceName := range instance.Spec.NicMappings {
+	networks, err := getAdditionalNetworks(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, network := range networks {
+		if err := validateNetworkConfig(name, network.config); err != nil {
+			return nil, err
+		}
 		nadSpec := netattdefv1.NetworkAttachmentDefinitionSpec{
-			Config: fmt.Sprintf(
-				`{"cniVersion": "0.3.1", "name": "%s", "type": "host-device", "device": "%s"}`,
-				physNet, interfaceName),
+			Config: network.config,
 		}
+		resourceName := network.resourceName
+
 		nad = &netattdefv1.NetworkAttachmentDefinition{}
 		err := h.GetClient().Get(
 			ctx,
 			client.ObjectKey{
 				Namespace: instance.Namespace,
-				Name:      physNet,
+				Name:      name,
 			},
 			nad,
 		)
 		if err != nil {
 			if !k8s_errors.IsNotFound(err) {
-				return nil, fmt.Errorf("cannot get NetworkAttachmentDefinition %s/%s: %w",
-					physNet, interfaceName, err)
+				return nil, fmt.Errorf("cannot get NetworkAttachmentDefinition %s: %w",
+					name, err)
 			}
 
 			ownerRef := metav1.NewControllerRef(instance, instance.GroupVersionKind())
 			nad = &netattdefv1.NetworkAttachmentDefinition{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:            physNet,
+					Name:            name,
 					Namespace:       instance.Namespace,
 					Labels:          labels,
 					OwnerReferences: []metav1.OwnerReference{*ownerRef},
 				},
 				Spec: nadSpec,
 			}
+			if resourceName != "" {
+				nad.Annotations = map[string]string{resourceNameAnnotation: resourceName}
+			}
 			// Request object not found, lets create it
 			if err := h.GetClient().Create(ctx, nad); err != nil {
-				return nil, fmt.Errorf("cannot create NetworkAttachmentDefinition %s/%s: %w",
-					physNet, interfaceName, err)
+				return nil, fmt.Errorf("cannot create NetworkAttachmentDefinition %s: %w",
+					name, err)
 			}
-		} else {
-			owned := false
-			for _, owner := range nad.GetOwnerReferences() {
-				if owner.Name == instance.Name {
-					owned = true
-					break
+		} else if metav1.IsControlledBy(nad, instance) {
+			nad.Spec = nadSpec
+			if resourceName != "" {
+				if nad.Annotations == nil {
+					nad.Annotations = map[string]string{}
 				}
+				nad.Annotations[resourceNameAnnotation] = resourceName
+			} else {
+				delete(nad.Annotations, resourceNameAnnotation)
 			}
-			if owned {
-				nad.Spec = nadSpec
-				if err := h.GetClient().Update(ctx, nad); err != nil {
-					return nil, fmt.Errorf("cannot update NetworkAttachmentDefinition %s/%s: %w",
-						physNet, interfaceName, err)
-				}
+			if err := h.GetClient().Update(ctx, nad); err != nil {
+				return nil, fmt.Errorf("cannot update NetworkAttachmentDefinition %s: %w",
+					name, err)
 			}
+		} else {
+			// Honour the existing definition, including the device plugin resource it relies on
+			h.GetLogger().Info(fmt.Sprintf("Using NetworkAttachmentDefinition %s not managed by %s as it is",
+				name, instance.Name))
+			resourceName = nad.Annotations[resourceNameAnnotation]
+			additionalNetworks.Unmanaged = append(additionalNetworks.Unmanaged, name)
 		}
 
-		networkAttachments = append(networkAttachments, physNet)
+		if resourceName != "" {
+			quantity := additionalNetworks.Resources[corev1.ResourceName(resourceName)]
+			quantity.Add(resource.MustParse("1"))
+			additionalNetworks.Resources[corev1.ResourceName(resourceName)] = quantity
+		}
+		additionalNetworks.Names = append(additionalNetworks.Names, name)
+	}
+	sort.Strings(additionalNetworks.Unmanaged)
+
+	return additionalNetworks, nil
+}
+
+// DeleteOrphanedAdditionalNetworks - deletes the NetworkAttachmentDefinitions carrying labels and owned by the
+// instance which are not part of networks anymore, e.g. after a physical network got removed from NicMappings
+func DeleteOrphanedAdditionalNetworks(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *ovnv1.OVNController,
+	labels map[string]string,
+	networks []string,
+) error {
+	nadList := &netattdefv1.NetworkAttachmentDefinitionList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels(labels),
+	}
+	if err := h.GetClient().List(ctx, nadList, listOpts...); err != nil {
+		return fmt.Errorf("cannot list NetworkAttachmentDefinitions: %w", err)
+	}
+
+	keep := map[string]bool{}
+	for _, name := range networks {
+		keep[name] = true
+	}
+
+	for i := range nadList.Items {
+		nad := &nadList.Items[i]
+		if keep[nad.Name] || !metav1.IsControlledBy(nad, instance) {
+			continue
+		}
+		if err := h.GetClient().Delete(ctx, nad); err != nil && !k8s_errors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete NetworkAttachmentDefinition %s: %w", nad.Name, err)
+		}
+		h.GetLogger().Info(fmt.Sprintf("Deleted orphaned NetworkAttachmentDefinition %s", nad.Name))
 	}
 
-	return networkAttachments, nil
+	return nil
 }
@@ -0,0 +1,151 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovncontroller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetNodeStatus - collects the per node state reported as annotations: the flow restores and
+// the offload state on the ovs pod of each node, the config agent state and the health of
+// ovn-controller on the ovn-controller pod
+func GetNodeStatus(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNController,
+) (map[string]ovnv1.OVNControllerNodeStatus, error) {
+	nodes := map[string]ovnv1.OVNControllerNodeStatus{}
+
+	ovsPods, err := getOVSPods(ctx, k8sClient, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range ovsPods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		nodeStatus := ovnv1.OVNControllerNodeStatus{}
+		nodeStatus.HardwareOffload = pod.Annotations[HardwareOffloadAnnotation]
+		if flows, err := strconv.ParseInt(pod.Annotations[OffloadedFlowsAnnotation], 10, 64); err == nil {
+			nodeStatus.OffloadedFlows = &flows
+		}
+		nodeStatus.FlowRestore = parseFlowRestore(pod.Annotations[FlowRestoreAnnotation])
+		nodes[pod.Spec.NodeName] = nodeStatus
+	}
+
+	ovnControllerPods, err := getServicePods(ctx, k8sClient, instance, ovnv1.ServiceNameOVNController)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range ovnControllerPods.Items {
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		nodeStatus := nodes[pod.Spec.NodeName]
+		if drift := pod.Annotations[BridgeDriftAnnotation]; drift != "" {
+			nodeStatus.BridgeDrift = strings.Split(drift, ";")
+		}
+		nodeStatus.ConfigGeneration = pod.Annotations[ConfigGenerationAnnotation]
+		nodeStatus.ConfigError = pod.Annotations[ConfigErrorAnnotation]
+		nodeStatus.OVNVersion = pod.Annotations[OVNVersionAnnotation]
+		nodeStatus.OVNImage = getContainerImage(pod, ovnv1.ServiceNameOVNController)
+		nodeStatus.RecomputeTime = parseMilliseconds(pod.Annotations[RecomputeTimeAnnotation])
+		nodeStatus.WaitBeforeClear = parseMilliseconds(pod.Annotations[WaitBeforeClearAnnotation])
+		nodeStatus.DegradedReason = getDegradedReason(pod)
+		nodeStatus.Degraded = nodeStatus.DegradedReason != ""
+		nodeStatus.RestartDowntime = parseMilliseconds(pod.Annotations[RestartDowntimeAnnotation])
+		nodes[pod.Spec.NodeName] = nodeStatus
+	}
+
+	return nodes, nil
+}
+
+// getContainerImage - returns the image of the container name of the pod
+func getContainerImage(pod corev1.Pod, name string) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+// getDegradedReason - returns why ovn-controller is degraded, empty when its pod is ready.
+// The failure its readiness probe reported is preferred over the pod state.
+func getDegradedReason(pod corev1.Pod) string {
+	if health := pod.Annotations[HealthAnnotation]; health != "" && health != "ok" {
+		return health
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			return fmt.Sprintf("container %s %s", status.Name, status.State.Waiting.Reason)
+		}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			if cond.Status == corev1.ConditionTrue {
+				return ""
+			}
+			break
+		}
+	}
+	return "ovn-controller not ready"
+}
+
+// parseFlowRestore - parses the FlowRestoreAnnotation, nil when it is not set or malformed
+func parseFlowRestore(annotation string) *ovnv1.OVNControllerFlowRestoreStatus {
+	fields := map[string]string{}
+	for _, field := range strings.Fields(annotation) {
+		if key, value, found := strings.Cut(field, "="); found {
+			fields[key] = value
+		}
+	}
+
+	restore := &ovnv1.OVNControllerFlowRestoreStatus{Result: fields["result"]}
+	if restore.Result != ovnv1.FlowRestoreSucceeded && restore.Result != ovnv1.FlowRestoreFailed {
+		return nil
+	}
+	var err error
+	if restore.SavedFlows, err = strconv.ParseInt(fields["saved"], 10, 64); err != nil {
+		return nil
+	}
+	if restore.RestoredFlows, err = strconv.ParseInt(fields["restored"], 10, 64); err != nil {
+		return nil
+	}
+	restoreTime, err := time.Parse(time.RFC3339, fields["time"])
+	if err != nil {
+		return nil
+	}
+	restore.Time = metav1.NewTime(restoreTime)
+
+	return restore
+}
+
+// parseMilliseconds - parses an annotation holding milliseconds, nil when it is not set or malformed
+func parseMilliseconds(annotation string) *metav1.Duration {
+	ms, err := strconv.ParseInt(annotation, 10, 64)
+	if err != nil || ms < 0 {
+		return nil
+	}
+	return &metav1.Duration{Duration: time.Duration(ms) * time.Millisecond}
+}
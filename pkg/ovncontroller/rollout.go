@@ -0,0 +1,166 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovncontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AgentConfigNodeKeyPrefix - keys of the agent ConfigMap holding the generation a node
+	// has to apply, nodes without key apply the AgentConfigGenerationKey one
+	AgentConfigNodeKeyPrefix = "node."
+)
+
+// RolloutConfig - moves the nodes to config generation following spec.configRollout and
+// returns the data of the agent ConfigMap. Nodes keep the generation they were given until
+// the rollout reaches them, so the config of those generations is carried over from the
+// current agent ConfigMap. The progress is recorded in Status.ConfigRollout, it relies on
+// Status.Nodes holding the generation each node reports.
+func RolloutConfig(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNController,
+	generation string,
+	config string,
+) (map[string]string, error) {
+	current := &corev1.ConfigMap{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: AgentConfigMapName(instance)}, current)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// the config agent runs in the ovn-controller pods
+	agentPods, err := getServicePods(ctx, k8sClient, instance, ovnv1.ServiceNameOVNController)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []string{}
+	for _, pod := range agentPods.Items {
+		if pod.Spec.NodeName != "" {
+			nodes = append(nodes, pod.Spec.NodeName)
+		}
+	}
+	sort.Strings(nodes)
+
+	rollout := instance.Status.ConfigRollout
+	if rollout == nil || rollout.Generation != generation {
+		rollout = &ovnv1.OVNControllerConfigRolloutStatus{Generation: generation}
+	}
+	pendingNodes := map[string]metav1.Time{}
+	failedNodes := map[string]string{}
+	now := metav1.Now()
+	deadline := time.Duration(instance.Spec.ConfigRollout.ProgressDeadlineSeconds) * time.Second
+
+	// generation each node has to apply, fresh nodes have nothing to disrupt and get the new one
+	assigned := map[string]string{}
+	for _, node := range nodes {
+		nodeGeneration := current.Data[AgentConfigNodeKeyPrefix+node]
+		if nodeGeneration == "" {
+			nodeGeneration = current.Data[AgentConfigGenerationKey]
+		}
+		if nodeGeneration == "" || instance.Status.Nodes[node].ConfigGeneration == "" ||
+			current.Data[nodeGeneration+".env"] == "" {
+			nodeGeneration = generation
+		}
+		assigned[node] = nodeGeneration
+	}
+
+	checkProgress := func(node string, startTime metav1.Time) {
+		nodeStatus := instance.Status.Nodes[node]
+		switch {
+		case nodeStatus.ConfigGeneration == generation:
+			rollout.UpdatedNodes++
+		case strings.HasPrefix(nodeStatus.ConfigError, generation+": "):
+			failedNodes[node] = strings.TrimPrefix(nodeStatus.ConfigError, generation+": ")
+		case rollout.FailedNodes[node] != "":
+			failedNodes[node] = rollout.FailedNodes[node]
+		case now.Sub(startTime.Time) > deadline:
+			failedNodes[node] = fmt.Sprintf("config not applied within %s", deadline)
+		default:
+			pendingNodes[node] = startTime
+		}
+	}
+
+	rollout.UpdatedNodes = 0
+	for _, node := range nodes {
+		if assigned[node] != generation {
+			continue
+		}
+		startTime, ok := rollout.PendingNodes[node]
+		if !ok {
+			startTime = now
+		}
+		checkProgress(node, startTime)
+	}
+
+	maxFailed, err := intstr.GetScaledValueFromIntOrPercent(instance.Spec.ConfigRollout.MaxFailed, len(nodes), false)
+	if err != nil {
+		return nil, err
+	}
+	rollout.Halted = len(failedNodes) > maxFailed
+	if !rollout.Halted {
+		maxParallel, err := intstr.GetScaledValueFromIntOrPercent(instance.Spec.ConfigRollout.MaxParallel, len(nodes), true)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if len(pendingNodes) >= max(maxParallel, 1) {
+				break
+			}
+			if assigned[node] != generation {
+				assigned[node] = generation
+				pendingNodes[node] = now
+			}
+		}
+	}
+	rollout.TotalNodes = int32(len(nodes))
+	rollout.PendingNodes = pendingNodes
+	rollout.FailedNodes = failedNodes
+	instance.Status.ConfigRollout = rollout
+
+	data := map[string]string{
+		AgentConfigGenerationKey: generation,
+		generation + ".env":      config,
+	}
+	for node, nodeGeneration := range assigned {
+		data[AgentConfigNodeKeyPrefix+node] = nodeGeneration
+		if nodeGeneration != generation {
+			data[nodeGeneration+".env"] = current.Data[nodeGeneration+".env"]
+		}
+	}
+
+	return data, nil
+}
+
+// GetRolloutFailedNodes - sorted, comma separated, nodes which failed to apply the config
+func GetRolloutFailedNodes(rollout *ovnv1.OVNControllerConfigRolloutStatus) string {
+	failed := []string{}
+	for node := range rollout.FailedNodes {
+		failed = append(failed, node)
+	}
+	sort.Strings(failed)
+	return strings.Join(failed, ",")
+}
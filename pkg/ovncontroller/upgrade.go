@@ -0,0 +1,40 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovncontroller
+
+import (
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// IsDaemonSetRolledOut - whether all the pods of the DaemonSet run its current template and are ready
+func IsDaemonSetRolledOut(daemonset appsv1.DaemonSet) bool {
+	return daemonset.Status.ObservedGeneration == daemonset.Generation &&
+		daemonset.Status.UpdatedNumberScheduled == daemonset.Status.DesiredNumberScheduled &&
+		daemonset.Status.NumberReady == daemonset.Status.DesiredNumberScheduled
+}
+
+// GetChassisOVNVersion - returns the OVN version every node reports, empty while
+// some nodes did not report it yet, report a different one or still run another
+// image than the target one
+func GetChassisOVNVersion(nodes map[string]ovnv1.OVNControllerNodeStatus, image string) string {
+	version := ""
+	for _, node := range nodes {
+		if node.OVNImage != image || node.OVNVersion == "" ||
+			(version != "" && node.OVNVersion != version) {
+			return ""
+		}
+		version = node.OVNVersion
+	}
+	return version
+}
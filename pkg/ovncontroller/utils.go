@@ -16,8 +16,10 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
 	"golang.org/x/exp/maps"
 	corev1 "k8s.io/api/core/v1"
@@ -37,10 +39,118 @@ func getPhysicalNetworks(
 	return strings.Join(nicMappings, " ")
 }
 
-func getOVNControllerPods(
+// getBridges - serializes spec.bridges for the configuration scripts, one
+// <bridge>;<physnets>;<port>;<interfaces>;<bond mode>;<lacp>;<trunks> entry
+// per bridge, lists being comma separated
+func getBridges(
+	instance *ovnv1.OVNController,
+) string {
+	bridges := []string{}
+	for _, br := range instance.Spec.Bridges {
+		port, interfaces, bondMode, lacp, trunks := "", "", "", "", ""
+		if br.Uplink != nil {
+			port = br.Uplink.Interfaces[0]
+			if len(br.Uplink.Interfaces) > 1 {
+				port = br.Uplink.GetBondName(br.Name)
+				bondMode = br.Uplink.BondMode
+				lacp = br.Uplink.LACP
+			}
+			interfaces = strings.Join(br.Uplink.Interfaces, ",")
+			vlans := []string{}
+			for _, vlan := range br.Uplink.Trunks {
+				vlans = append(vlans, fmt.Sprintf("%d", vlan))
+			}
+			trunks = strings.Join(vlans, ",")
+		}
+		bridges = append(bridges, strings.Join([]string{
+			br.Name, strings.Join(br.PhysicalNetworks, ","), port, interfaces, bondMode, lacp, trunks,
+		}, ";"))
+	}
+	return strings.Join(bridges, " ")
+}
+
+// getExternalIDs - serializes the tunable and extra external_ids as space separated <key>=<value> entries
+func getExternalIDs(
+	instance *ovnv1.OVNController,
+) string {
+	ids := instance.Spec.ExternalIDS
+	externalIDs := map[string]string{}
+	for key, value := range ids.ExtraExternalIDs {
+		externalIDs[key] = value
+	}
+	if ids.OvnRemoteProbeInterval != nil {
+		externalIDs["ovn-remote-probe-interval"] = strconv.Itoa(int(*ids.OvnRemoteProbeInterval))
+	}
+	if ids.OvnOpenflowProbeInterval != nil {
+		externalIDs["ovn-openflow-probe-interval"] = strconv.Itoa(int(*ids.OvnOpenflowProbeInterval))
+	}
+	if ids.OvnMonitorAll != nil {
+		externalIDs["ovn-monitor-all"] = strconv.FormatBool(*ids.OvnMonitorAll)
+	}
+	if ids.OvnOfctrlWaitBeforeClear != nil {
+		externalIDs["ovn-ofctrl-wait-before-clear"] = strconv.Itoa(int(*ids.OvnOfctrlWaitBeforeClear))
+	}
+	if ids.OvnEncapTos != "" {
+		externalIDs["ovn-encap-tos"] = ids.OvnEncapTos
+	}
+	if ids.OvnMatchNorthdVersion != nil {
+		externalIDs["ovn-match-northd-version"] = strconv.FormatBool(*ids.OvnMatchNorthdVersion)
+	}
+
+	entries := []string{}
+	for key, value := range externalIDs {
+		entries = append(entries, key+"="+value)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, " ")
+}
+
+// getDPDKDevices - serializes spec.dpdk.devices as space separated <interface>=<dpdk-devargs> entries
+func getDPDKDevices(
+	instance *ovnv1.OVNController,
+) string {
+	if instance.Spec.DPDK == nil {
+		return ""
+	}
+	devices := []string{}
+	for iface, devargs := range instance.Spec.DPDK.Devices {
+		devices = append(devices, iface+"="+devargs)
+	}
+	sort.Strings(devices)
+	return strings.Join(devices, " ")
+}
+
+// getDPDKEnvVars - DPDK settings applied by start-vswitchd.sh and the config agent
+func getDPDKEnvVars(
+	instance *ovnv1.OVNController,
+) map[string]env.Setter {
+	envVars := map[string]env.Setter{}
+	dpdk := instance.Spec.DPDK
+	if dpdk == nil {
+		return envVars
+	}
+	envVars["OVSDatapathType"] = env.SetValue("netdev")
+	envVars["OVSDPDKInit"] = env.SetValue(dpdk.DPDKInit)
+	envVars["OVSDPDKSocketMem"] = env.SetValue(dpdk.SocketMemory)
+	envVars["OVSDPDKLcoreMask"] = env.SetValue(dpdk.LcoreMask)
+	envVars["OVSPMDCPUMask"] = env.SetValue(dpdk.PMDCPUMask)
+	envVars["OVSDPDKDevices"] = env.SetValue(getDPDKDevices(instance))
+	return envVars
+}
+
+func getOVSPods(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNController,
+) (*corev1.PodList, error) {
+	return getServicePods(ctx, k8sClient, instance, ovnv1.ServiceNameOVS)
+}
+
+func getServicePods(
 	ctx context.Context,
 	k8sClient client.Client,
 	instance *ovnv1.OVNController,
+	serviceName string,
 ) (*corev1.PodList, error) {
 
 	podList := &corev1.PodList{}
@@ -48,7 +158,7 @@ func getOVNControllerPods(
 		Namespace: instance.Namespace,
 	}
 	client.MatchingLabels{
-		"service": ovnv1.ServiceNameOVNController,
+		"service": serviceName,
 	}.ApplyToList(podListOpts)
 
 	if err := k8sClient.List(ctx, podList, podListOpts); err != nil {
@@ -1,12 +1,13 @@
 package ovncontroller
 
 import (
-	"fmt"
+	"path"
 
 	corev1 "k8s.io/api/core/v1"
 )
 
-func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
+// GetOVNControllerVolumes - ovn-controller Volumes, host state is kept under hostStateDir/namespace
+func GetOVNControllerVolumes(name string, namespace string, hostStateDir string) []corev1.Volume {
 
 	var scriptsVolumeDefaultMode int32 = 0755
 	directoryOrCreate := corev1.HostPathDirectoryOrCreate
@@ -17,7 +18,7 @@ func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
 			Name: "etc-ovs",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/etc/ovs", namespace),
+					Path: path.Join(hostStateDir, namespace, "etc/ovs"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -26,7 +27,7 @@ func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-run",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/run/openvswitch", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/run/openvswitch"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -35,7 +36,7 @@ func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-log",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/log/openvswitch", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/log/openvswitch"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -44,7 +45,7 @@ func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-lib",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/lib/openvswitch", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/lib/openvswitch"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -53,7 +54,7 @@ func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-run-ovn",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/run/ovn", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/run/ovn"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -62,7 +63,7 @@ func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-log-ovn",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/log/ovn", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/log/ovn"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -82,7 +83,8 @@ func GetOVNControllerVolumes(name string, namespace string) []corev1.Volume {
 
 }
 
-func GetOVSVolumes(name string, namespace string) []corev1.Volume {
+// GetOVSVolumes - ovsdb-server and ovs-vswitchd Volumes, host state is kept under hostStateDir/namespace
+func GetOVSVolumes(name string, namespace string, hostStateDir string) []corev1.Volume {
 
 	var scriptsVolumeDefaultMode int32 = 0755
 	directoryOrCreate := corev1.HostPathDirectoryOrCreate
@@ -93,7 +95,7 @@ func GetOVSVolumes(name string, namespace string) []corev1.Volume {
 			Name: "etc-ovs",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/etc/ovs", namespace),
+					Path: path.Join(hostStateDir, namespace, "etc/ovs"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -102,7 +104,7 @@ func GetOVSVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-run",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/run/openvswitch", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/run/openvswitch"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -111,7 +113,7 @@ func GetOVSVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-log",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/log/openvswitch", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/log/openvswitch"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -120,7 +122,7 @@ func GetOVSVolumes(name string, namespace string) []corev1.Volume {
 			Name: "var-lib",
 			VolumeSource: corev1.VolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
-					Path: fmt.Sprintf("/var/home/core/%s/var/lib/openvswitch", namespace),
+					Path: path.Join(hostStateDir, namespace, "var/lib/openvswitch"),
 					Type: &directoryOrCreate,
 				},
 			},
@@ -222,3 +224,120 @@ func GetOVNControllerVolumeMounts() []corev1.VolumeMount {
 		},
 	}
 }
+
+// GetDPDKVolumes - hugepages and VFIO devices used by ovs-vswitchd with DPDK
+func GetDPDKVolumes(hugepageSize string) []corev1.Volume {
+	directory := corev1.HostPathDirectory
+
+	return []corev1.Volume{
+		{
+			Name: "hugepages",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumHugePagesPrefix + corev1.StorageMedium(hugepageSize),
+				},
+			},
+		},
+		{
+			Name: "dev-vfio",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/dev/vfio",
+					Type: &directory,
+				},
+			},
+		},
+	}
+}
+
+// GetDPDKVolumeMounts - ovs-vswitchd VolumeMounts with DPDK
+func GetDPDKVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      "hugepages",
+			MountPath: "/dev/hugepages",
+			ReadOnly:  false,
+		},
+		{
+			Name:      "dev-vfio",
+			MountPath: "/dev/vfio",
+			ReadOnly:  false,
+		},
+	}
+}
+
+// GetHostProcVolume - host /proc, used to enter the host network namespace
+func GetHostProcVolume() corev1.Volume {
+	directory := corev1.HostPathDirectory
+
+	return corev1.Volume{
+		Name: "host-proc",
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: "/proc",
+				Type: &directory,
+			},
+		},
+	}
+}
+
+// GetSwitchdevVolumeMounts - VolumeMounts of the init container switching PFs to switchdev mode
+func GetSwitchdevVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      "host-proc",
+			MountPath: "/host/proc",
+			ReadOnly:  true,
+		},
+		{
+			Name:      "scripts",
+			MountPath: "/usr/local/bin/container-scripts",
+			ReadOnly:  true,
+		},
+	}
+}
+
+// GetAgentConfigVolume - ConfigMap rendered by the operator for the ovn-config-agent container. It is
+// optional so the ovn-controller pods start before the SB DB is known, the agent waits for it.
+func GetAgentConfigVolume(name string) corev1.Volume {
+	optional := true
+
+	return corev1.Volume{
+		Name: "agent-config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: name + "-agent-config",
+				},
+				Optional: &optional,
+			},
+		},
+	}
+}
+
+// GetAgentVolumeMounts - ovn-config-agent VolumeMounts
+func GetAgentVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      "var-run",
+			MountPath: "/var/run/openvswitch",
+			ReadOnly:  false,
+		},
+		// run directory of ovn-controller, to measure its recompute time with gracefulRestart
+		{
+			Name:      "var-run-ovn",
+			MountPath: "/var/run/ovn",
+			ReadOnly:  false,
+		},
+		{
+			Name:      "agent-config",
+			MountPath: AgentConfigDir,
+			ReadOnly:  true,
+		},
+		{
+			Name:      "scripts",
+			MountPath: "/usr/local/bin/container-scripts",
+			ReadOnly:  true,
+		},
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovndbcluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
+)
+
+const (
+	// ConnectionsCheckInterval - how often the connections of the members are checked while
+	// the ready client pods do not change
+	ConnectionsCheckInterval = 60 * time.Second
+
+	// ConnectionsRetryInterval - time between two checks of the connections of the members
+	// while the ready client pods keep changing
+	ConnectionsRetryInterval = 5 * time.Second
+
+	// ConnectionsQueryTimeout - time each command querying the connections of a member may take
+	ConnectionsQueryTimeout = 10 * time.Second
+
+	// ClientPodsHash - hash of the ready client pods the connections got checked with
+	ClientPodsHash = "clientpods"
+
+	// ConnectionsDropPercent - drop of the client connections since the previous check
+	// which is reported
+	ConnectionsDropPercent = 50
+
+	// ConnectionsDropMinimum - client connections below which no drop is reported, a few
+	// restarting clients are not worth a warning
+	ConnectionsDropMinimum = 4
+)
+
+// AppctlCommand - ovn-appctl command run against the ovsdb-server of a member of instance
+func AppctlCommand(dbType string, args ...string) []string {
+	return append([]string{"ovn-appctl", "-t", fmt.Sprintf("/tmp/ovn%s_db.ctl", strings.ToLower(dbType))}, args...)
+}
+
+// GetConnections - connections of the member of each running pod, keyed by pod name. The
+// members are queried concurrently, each command bounded to ConnectionsQueryTimeout. Members
+// which could not be queried are left out, their pod names are returned sorted.
+func GetConnections(
+	ctx context.Context,
+	executor ovn_common.PodExecutor,
+	instance *ovnv1.OVNDBCluster,
+	pods []corev1.Pod,
+) (map[string]ovnv1.OVNDBConnections, []string) {
+	container := ovnv1.ServiceNameNB
+	if instance.Spec.DBType == ovnv1.SBDBType {
+		container = ovnv1.ServiceNameSB
+	}
+	exec := func(pod *corev1.Pod, args ...string) (string, error) {
+		execCtx, cancel := context.WithTimeout(ctx, ConnectionsQueryTimeout)
+		defer cancel()
+		return executor.Exec(execCtx, pod, container, AppctlCommand(instance.Spec.DBType, args...))
+	}
+
+	members := make([]*ovnv1.OVNDBConnections, len(pods))
+	var wg sync.WaitGroup
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			output, err := exec(pod, "memory/show")
+			if err != nil {
+				return
+			}
+			usage := parseMemoryShow(output)
+			member := &ovnv1.OVNDBConnections{
+				Sessions:        usage["sessions"],
+				Monitors:        usage["monitors"],
+				RaftConnections: usage["raft-connections"],
+			}
+			output, err = exec(pod, "ovsdb-server/list-remotes")
+			if err == nil {
+				member.Remotes = strings.Fields(output)
+			}
+			members[i] = member
+		}(i)
+	}
+	wg.Wait()
+
+	connections := map[string]ovnv1.OVNDBConnections{}
+	unqueried := []string{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if members[i] == nil {
+			unqueried = append(unqueried, pod.Name)
+			continue
+		}
+		connections[pod.Name] = *members[i]
+	}
+	sort.Strings(unqueried)
+
+	return connections, unqueried
+}
+
+// parseMemoryShow - counters of the "name:value" fields of memory/show
+func parseMemoryShow(output string) map[string]int64 {
+	usage := map[string]int64{}
+	for _, field := range strings.Fields(output) {
+		name, value, found := strings.Cut(field, ":")
+		if !found {
+			continue
+		}
+		if count, err := strconv.ParseInt(value, 10, 64); err == nil {
+			usage[name] = count
+		}
+	}
+	return usage
+}
+
+// GetClientConnections - client sessions of all the members
+func GetClientConnections(connections map[string]ovnv1.OVNDBConnections) int64 {
+	var total int64
+	for _, member := range connections {
+		total += member.Sessions
+	}
+	return total
+}
+
+// IsConnectionsDrop - whether the client connections dropped sharply from previous to current
+func IsConnectionsDrop(previous int64, current int64) bool {
+	return previous >= ConnectionsDropMinimum && current*100 < previous*(100-ConnectionsDropPercent)
+}
+
+// ClientServices - services of the pods which connect to a DB cluster of dbType as clients
+func ClientServices(dbType string) []string {
+	if dbType == ovnv1.SBDBType {
+		return []string{ovnv1.ServiceNameOVNNorthd, ovnv1.ServiceNameOVNController}
+	}
+	return []string{ovnv1.ServiceNameOVNNorthd}
+}
+
+// GetClientPodsHash - hash of the names of the ready client pods of instance in its
+// namespace. Clients going away or coming back change it.
+func GetClientPodsHash(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNDBCluster,
+) (string, error) {
+	services, err := labels.NewRequirement(common.AppSelector, selection.In, ClientServices(instance.Spec.DBType))
+	if err != nil {
+		return "", err
+	}
+	podList := &corev1.PodList{}
+	err = k8sClient.List(ctx, podList, client.InNamespace(instance.Namespace),
+		client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*services)})
+	if err != nil {
+		return "", err
+	}
+
+	ready := []string{}
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp == nil && isPodReady(&pod) {
+			ready = append(ready, pod.Name)
+		}
+	}
+	sort.Strings(ready)
+
+	return util.ObjectHash(ready)
+}
+
+// isPodReady - whether the Ready condition of pod is True
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -41,6 +41,7 @@ func StatefulSet(
 	configHash string,
 	labels map[string]string,
 	annotations map[string]string,
+	containerImage string,
 ) *appsv1.StatefulSet {
 	livenessProbe := &corev1.Probe{
 		// TODO might need tuning
@@ -153,7 +154,7 @@ func StatefulSet(
 							Name:                     serviceName,
 							Command:                  cmd,
 							Args:                     args,
-							Image:                    instance.Spec.ContainerImage,
+							Image:                    containerImage,
 							Env:                      env.MergeEnvs([]corev1.EnvVar{}, envVars),
 							VolumeMounts:             volumeMounts,
 							Resources:                instance.Spec.Resources,
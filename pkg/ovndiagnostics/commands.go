@@ -0,0 +1,194 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovndiagnostics
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovndbcluster"
+	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovnnorthd"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TargetOVSVswitchd - spec.target running ovs-appctl against ovs-vswitchd
+	TargetOVSVswitchd = "ovs-vswitchd"
+)
+
+// target - where and how the commands of a spec.target run
+type target struct {
+	// service - service label of the pods
+	service string
+	// container - container of the pods the commands run in
+	container string
+	// command - command line running the appctl command with its arguments
+	command func(args ...string) []string
+	// allowed - read only commands which can be run, with the arguments they get
+	allowed map[string][]string
+}
+
+// commonCommands - read only commands served by every OVS/OVN daemon
+var commonCommands = map[string][]string{
+	"coverage/show": nil,
+	"memory/show":   nil,
+	"vlog/list":     nil,
+}
+
+func dbTarget(service string, dbType string, dbName string) target {
+	return target{
+		service:   service,
+		container: service,
+		command: func(args ...string) []string {
+			return ovndbcluster.AppctlCommand(dbType, args...)
+		},
+		allowed: withCommonCommands(map[string][]string{
+			"cluster/status":            {dbName},
+			"ovsdb-server/list-dbs":     nil,
+			"ovsdb-server/list-remotes": nil,
+			"ovsdb-server/sync-status":  nil,
+		}),
+	}
+}
+
+func withCommonCommands(commands map[string][]string) map[string][]string {
+	for command, args := range commonCommands {
+		commands[command] = args
+	}
+	return commands
+}
+
+var targets = map[string]target{
+	ovnv1.ServiceNameOVNNorthd: {
+		service:   ovnv1.ServiceNameOVNNorthd,
+		container: ovnv1.ServiceNameOVNNorthd,
+		command:   ovnnorthd.AppctlCommand,
+		allowed: withCommonCommands(map[string][]string{
+			"status":                nil,
+			"inc-engine/show-stats": nil,
+			"nb-connection-status":  nil,
+			"sb-connection-status":  nil,
+		}),
+	},
+	ovnv1.ServiceNameNB: dbTarget(ovnv1.ServiceNameNB, ovnv1.NBDBType, "OVN_Northbound"),
+	ovnv1.ServiceNameSB: dbTarget(ovnv1.ServiceNameSB, ovnv1.SBDBType, "OVN_Southbound"),
+	ovnv1.ServiceNameOVNController: {
+		service:   ovnv1.ServiceNameOVNController,
+		container: "ovn-controller",
+		command: func(args ...string) []string {
+			return append([]string{"ovn-appctl", "-t", "ovn-controller"}, args...)
+		},
+		allowed: withCommonCommands(map[string][]string{
+			"connection-status":         nil,
+			"inc-engine/show-stats":     nil,
+			"lflow-cache/show-stats":    nil,
+			"debug/dump-local-bindings": nil,
+		}),
+	},
+	TargetOVSVswitchd: {
+		service:   ovnv1.ServiceNameOVS,
+		container: TargetOVSVswitchd,
+		command: func(args ...string) []string {
+			return append([]string{"ovs-appctl"}, args...)
+		},
+		allowed: withCommonCommands(map[string][]string{
+			"dpif/show":   nil,
+			"upcall/show": nil,
+			"bond/show":   nil,
+		}),
+	},
+}
+
+// Command - a command to run in a pod, with the ConfigMap key of its output
+type Command struct {
+	Pod       *corev1.Pod
+	Container string
+	Command   []string
+	Key       string
+}
+
+// GetRefusedCommands - sorted commands of instance which are not allowed for its target
+func GetRefusedCommands(instance *ovnv1.OVNDiagnostics) []string {
+	refused := []string{}
+	for _, command := range instance.Spec.Commands {
+		if _, ok := targets[instance.Spec.Target].allowed[command]; !ok {
+			refused = append(refused, command)
+		}
+	}
+	sort.Strings(refused)
+	return refused
+}
+
+// OutputKey - ConfigMap key of the output of command in pod
+func OutputKey(pod string, command string) string {
+	return pod + "." + strings.ReplaceAll(command, "/", "-")
+}
+
+// GetCommands - commands of instance to run in each selected running pod of its target,
+// in OVNDiagnosticsMaxPods pods at most and OVNDiagnosticsMaxCommands commands over all pods.
+// Returns the number of running pods left out too.
+func GetCommands(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNDiagnostics,
+) ([]Command, int32, error) {
+	target, ok := targets[instance.Spec.Target]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown target %s", instance.Spec.Target)
+	}
+
+	podList := &corev1.PodList{}
+	err := k8sClient.List(ctx, podList, client.InNamespace(instance.Namespace),
+		client.MatchingLabels{common.AppSelector: target.service})
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(podList.Items, func(i, j int) bool { return podList.Items[i].Name < podList.Items[j].Name })
+
+	perPod := max(len(instance.Spec.Commands), 1)
+	maxPods := min(ovnv1.OVNDiagnosticsMaxPods, max(ovnv1.OVNDiagnosticsMaxCommands/perPod, 1))
+	commands := []Command{}
+	pods := 0
+	skipped := int32(0)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if len(instance.Spec.Pods) > 0 && !slices.Contains(instance.Spec.Pods, pod.Name) {
+			continue
+		}
+		if pods == maxPods {
+			skipped++
+			continue
+		}
+		pods++
+		for _, command := range instance.Spec.Commands {
+			commands = append(commands, Command{
+				Pod:       pod,
+				Container: target.container,
+				Command:   target.command(append([]string{command}, target.allowed[command]...)...),
+				Key:       OutputKey(pod.Name, command),
+			})
+		}
+	}
+
+	return commands, skipped, nil
+}
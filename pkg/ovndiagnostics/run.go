@@ -0,0 +1,89 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovndiagnostics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
+)
+
+const (
+	// CommandTimeout - time a command may take, its output is the timeout error after it
+	CommandTimeout = 10 * time.Second
+
+	// MaxConcurrentCommands - commands running at the same time
+	MaxConcurrentCommands = 5
+
+	// MaxCommandOutput - bytes of the output of a command stored in the output ConfigMap
+	MaxCommandOutput = 64 * 1024
+
+	// MaxOutput - bytes of output of all the commands stored in the output ConfigMap,
+	// which has to stay under the 1MiB limit of the objects of the API server
+	MaxOutput = 900 * 1024
+
+	// truncatedMarker - appended to a truncated output
+	truncatedMarker = "\n[output truncated]\n"
+)
+
+// Result - outputs of commands keyed by their ConfigMap key, with the keys of the ones
+// which failed and of the ones whose output got truncated
+type Result struct {
+	Output    map[string]string
+	Failed    []string
+	Truncated []string
+}
+
+// Run - runs commands, at most MaxConcurrentCommands at a time and each bounded to
+// CommandTimeout. The output of a command is cut to MaxCommandOutput bytes, the outputs
+// are cut, in the order of commands, once they add up to MaxOutput bytes.
+func Run(ctx context.Context, executor ovn_common.PodExecutor, commands []Command) Result {
+	outputs := make([]string, len(commands))
+	errs := make([]error, len(commands))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, MaxConcurrentCommands)
+	for i := range commands {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer func() { <-slots }()
+			defer wg.Done()
+			cmdCtx, cancel := context.WithTimeout(ctx, CommandTimeout)
+			defer cancel()
+			command := commands[i]
+			outputs[i], errs[i] = executor.Exec(cmdCtx, command.Pod, command.Container, command.Command)
+		}(i)
+	}
+	wg.Wait()
+
+	result := Result{Output: map[string]string{}, Failed: []string{}, Truncated: []string{}}
+	left := MaxOutput
+	for i, command := range commands {
+		output := outputs[i]
+		if errs[i] != nil {
+			output = errs[i].Error()
+			result.Failed = append(result.Failed, command.Key)
+		}
+		if limit := min(MaxCommandOutput, left); len(output) > limit {
+			output = output[:max(limit-len(truncatedMarker), 0)] + truncatedMarker
+			result.Truncated = append(result.Truncated, command.Key)
+		}
+		left = max(left-len(output), 0)
+		result.Output[command.Key] = output
+	}
+
+	return result
+}
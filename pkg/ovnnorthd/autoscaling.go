@@ -0,0 +1,257 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovnnorthd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SBCellsPerThread - cells of the SB DB sized per ovn-northd thread. The cells of every
+	// SB DB table are counted, as a proxy of the logical flows, whose rows make most of them.
+	SBCellsPerThread = 500000
+
+	// CPUPerThreadMillis - cpu request in millicores sized per ovn-northd thread
+	CPUPerThreadMillis = 500
+
+	// AutoscalingInterval - how often the SB DB cells and the memory usage are collected
+	AutoscalingInterval = 5 * time.Minute
+
+	// ScaleDownStabilization - how long the collected inputs have to ask for fewer threads
+	// before they get removed
+	ScaleDownStabilization = 15 * time.Minute
+
+	// InstanceWarmup - inputs are not collected through an ovn-northd instance started more
+	// recently, it could still be loading the NB and SB DBs
+	InstanceWarmup = 5 * time.Minute
+
+	// memoryHeadroomPercent - memory requested on top of the memory usage
+	memoryHeadroomPercent = 25
+
+	// memoryHysteresisPercent - the previous memory request is kept while the sized one
+	// differs by less, which avoids rolling ovn-northd out after every collection
+	memoryHysteresisPercent = 20
+
+	// nThreadsTolerancePercent - the previous number of threads is kept while the SB DB
+	// cells stay within this band around what it is sized for
+	nThreadsTolerancePercent = 10
+
+	// sbCellsKey - memory/show key of the cells of the SB DB held by ovn-northd
+	sbCellsKey = "idl-cells-OVN_Southbound:"
+)
+
+// SBCellsCommand - ovn-appctl command printing the memory usage report of ovn-northd, with
+// the cells of all the SB DB tables it holds. ovn-northd does not report the number of
+// logical flows, and listing Logical_Flow would load the SB DB and ovn-northd, so these
+// cells stand for the logical flows.
+func SBCellsCommand() []string {
+	return AppctlCommand("memory/show")
+}
+
+// MemoryUsageCommand - command printing the resident memory of ovn-northd in KiB
+func MemoryUsageCommand() []string {
+	return []string{"/bin/bash", "-c", "awk '/^VmRSS:/ {print $2}' /proc/$(pidof ovn-northd)/status"}
+}
+
+// IsWarmingUp - whether the ovn-northd container of pod started less than InstanceWarmup
+// before now. Its inputs do not reflect the load then.
+func IsWarmingUp(pod *corev1.Pod, now time.Time) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == ovnv1.ServiceNameOVNNorthd && status.State.Running != nil {
+			return now.Sub(status.State.Running.StartedAt.Time) < InstanceWarmup
+		}
+	}
+	return false
+}
+
+// CollectAutoscalingInputs - cells of the SB DB and memory usage of the active ovn-northd
+// instance, collected through its pod
+func CollectAutoscalingInputs(
+	ctx context.Context,
+	executor ovn_common.PodExecutor,
+	pod *corev1.Pod,
+) (int64, *resource.Quantity, error) {
+	output, err := executor.Exec(ctx, pod, ovnv1.ServiceNameOVNNorthd, SBCellsCommand())
+	if err != nil {
+		return 0, nil, err
+	}
+	cells, err := parseSBCells(output)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	output, err = executor.Exec(ctx, pod, ovnv1.ServiceNameOVNNorthd, MemoryUsageCommand())
+	if err != nil {
+		return 0, nil, err
+	}
+	kib, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("unexpected memory usage %q: %w", output, err)
+	}
+
+	return cells, resource.NewQuantity(kib*1024, resource.BinarySI), nil
+}
+
+// parseSBCells - value of idl-cells-OVN_Southbound in the space separated key:value pairs
+// of the memory/show output
+func parseSBCells(output string) (int64, error) {
+	for _, field := range strings.Fields(output) {
+		if value, found := strings.CutPrefix(field, sbCellsKey); found {
+			cells, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("unexpected number of SB DB cells %q: %w", value, err)
+			}
+			return cells, nil
+		}
+	}
+	return 0, fmt.Errorf("no %s in the memory report %q", sbCellsKey, output)
+}
+
+// GetAutoscalingStatus - sizing of ovn-northd for the inputs, within the bounds of
+// spec.autoscaling. The number of threads and the memory request of the previous sizing
+// are kept while they are close, and threads only get removed once the inputs asked for
+// fewer of them for ScaleDownStabilization.
+func GetAutoscalingStatus(
+	instance *ovnv1.OVNNorthd,
+	podName string,
+	sbCells int64,
+	memoryUsage resource.Quantity,
+	collectionTime metav1.Time,
+) *ovnv1.OVNNorthdAutoscalingStatus {
+	autoscaling := instance.Spec.Autoscaling
+	autoscaling.Default()
+	previous := instance.Status.Autoscaling
+
+	nThreads, scaleDownTime := sizeNThreads(sbCells, previous, collectionTime)
+	nThreads = max(nThreads, *autoscaling.MinNThreads)
+	nThreads = min(nThreads, *autoscaling.MaxNThreads)
+
+	cpu := resource.NewMilliQuantity(int64(nThreads)*CPUPerThreadMillis, resource.DecimalSI)
+	memory := resource.NewQuantity(memoryUsage.Value()*(100+memoryHeadroomPercent)/100, resource.BinarySI)
+	if previous != nil {
+		if request, ok := previous.Requests[corev1.ResourceMemory]; ok {
+			delta := memory.Value() - request.Value()
+			if delta < 0 {
+				delta = -delta
+			}
+			if delta*100 < request.Value()*memoryHysteresisPercent {
+				memory = &request
+			}
+		}
+	}
+
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:    withinBounds(corev1.ResourceCPU, *cpu, autoscaling),
+		corev1.ResourceMemory: withinBounds(corev1.ResourceMemory, *memory, autoscaling),
+	}
+
+	return &ovnv1.OVNNorthdAutoscalingStatus{
+		SBCells:        sbCells,
+		MemoryUsage:    memoryUsage,
+		Instance:       podName,
+		CollectionTime: collectionTime,
+		NThreads:       nThreads,
+		ScaleDownTime:  scaleDownTime,
+		Requests:       requests,
+		Applied:        autoscaling.Mode == ovnv1.OVNNorthdAutoscalingAuto,
+		Reason: fmt.Sprintf("%d cells of all SB DB tables at %d per thread need %d thread(s) within [%d,%d], "+
+			"memory usage %s plus %d%% headroom",
+			sbCells, SBCellsPerThread, nThreads, *autoscaling.MinNThreads, *autoscaling.MaxNThreads,
+			memoryUsage.String(), memoryHeadroomPercent),
+	}
+}
+
+// sizeNThreads - number of threads for sbCells, without bounds. Compared to the previous
+// sizing, threads get added once the cells exceed what it is sized for by more than
+// nThreadsTolerancePercent, and removed once they stay below what fewer threads are sized
+// for by as much since the returned scale down time for ScaleDownStabilization.
+func sizeNThreads(
+	sbCells int64,
+	previous *ovnv1.OVNNorthdAutoscalingStatus,
+	collectionTime metav1.Time,
+) (int32, *metav1.Time) {
+	if previous == nil || previous.NThreads == 0 {
+		return threadsFor(sbCells, SBCellsPerThread), nil
+	}
+
+	up := threadsFor(sbCells, SBCellsPerThread*(100+nThreadsTolerancePercent)/100)
+	down := threadsFor(sbCells, SBCellsPerThread*(100-nThreadsTolerancePercent)/100)
+	switch {
+	case up > previous.NThreads:
+		return up, nil
+	case down >= previous.NThreads:
+		return previous.NThreads, nil
+	case previous.ScaleDownTime == nil:
+		return previous.NThreads, &collectionTime
+	case collectionTime.Sub(previous.ScaleDownTime.Time) < ScaleDownStabilization:
+		return previous.NThreads, previous.ScaleDownTime
+	default:
+		return down, nil
+	}
+}
+
+// threadsFor - threads for sbCells at perThread cells each
+func threadsFor(sbCells int64, perThread int64) int32 {
+	return int32((sbCells + perThread - 1) / perThread)
+}
+
+func withinBounds(name corev1.ResourceName, quantity resource.Quantity, autoscaling ovnv1.OVNNorthdAutoscaling) resource.Quantity {
+	if bound, ok := autoscaling.MinRequests[name]; ok && quantity.Cmp(bound) < 0 {
+		return bound
+	}
+	if bound, ok := autoscaling.MaxRequests[name]; ok && quantity.Cmp(bound) > 0 {
+		return bound
+	}
+	return quantity
+}
+
+// getNThreads - number of threads ovn-northd gets deployed with
+func getNThreads(instance *ovnv1.OVNNorthd) int32 {
+	if status := instance.Status.Autoscaling; status != nil && status.Applied &&
+		instance.Spec.Autoscaling.Mode == ovnv1.OVNNorthdAutoscalingAuto {
+		return status.NThreads
+	}
+	return *instance.Spec.NThreads
+}
+
+// getResources - resources ovn-northd gets deployed with. The sized requests do not exceed
+// the limits of spec.resources.
+func getResources(instance *ovnv1.OVNNorthd) corev1.ResourceRequirements {
+	resources := *instance.Spec.Resources.DeepCopy()
+	status := instance.Status.Autoscaling
+	if status == nil || !status.Applied || instance.Spec.Autoscaling.Mode != ovnv1.OVNNorthdAutoscalingAuto {
+		return resources
+	}
+
+	if resources.Requests == nil {
+		resources.Requests = corev1.ResourceList{}
+	}
+	for name, request := range status.Requests {
+		if limit, ok := resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			request = limit
+		}
+		resources.Requests[name] = request
+	}
+	return resources
+}
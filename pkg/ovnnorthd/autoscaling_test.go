@@ -0,0 +1,167 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovnnorthd
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega" //revive:disable:dot-imports
+
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSizeNThreads(t *testing.T) {
+	start := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	at := func(d time.Duration) metav1.Time { return metav1.NewTime(start.Add(d)) }
+
+	tests := []struct {
+		name              string
+		sbCells           int64
+		previous          *ovnv1.OVNNorthdAutoscalingStatus
+		collectionTime    metav1.Time
+		wantNThreads      int32
+		wantScaleDownTime *metav1.Time
+	}{
+		{
+			name:           "first sizing",
+			sbCells:        1200000,
+			collectionTime: start,
+			wantNThreads:   3,
+		},
+		{
+			name:           "within the band of the previous sizing",
+			sbCells:        1050000,
+			previous:       &ovnv1.OVNNorthdAutoscalingStatus{NThreads: 2},
+			collectionTime: start,
+			wantNThreads:   2,
+		},
+		{
+			name:           "above the band scales up at once",
+			sbCells:        1110000,
+			previous:       &ovnv1.OVNNorthdAutoscalingStatus{NThreads: 2},
+			collectionTime: start,
+			wantNThreads:   3,
+		},
+		{
+			name:              "below the band starts the scale down window",
+			sbCells:           800000,
+			previous:          &ovnv1.OVNNorthdAutoscalingStatus{NThreads: 3},
+			collectionTime:    start,
+			wantNThreads:      3,
+			wantScaleDownTime: &start,
+		},
+		{
+			name:    "below the band within the scale down window",
+			sbCells: 800000,
+			previous: &ovnv1.OVNNorthdAutoscalingStatus{
+				NThreads: 3, ScaleDownTime: &start,
+			},
+			collectionTime:    at(ScaleDownStabilization - time.Minute),
+			wantNThreads:      3,
+			wantScaleDownTime: &start,
+		},
+		{
+			name:    "below the band for the whole scale down window",
+			sbCells: 800000,
+			previous: &ovnv1.OVNNorthdAutoscalingStatus{
+				NThreads: 3, ScaleDownTime: &start,
+			},
+			collectionTime: at(ScaleDownStabilization),
+			wantNThreads:   2,
+		},
+		{
+			name:    "back within the band resets the scale down window",
+			sbCells: 1300000,
+			previous: &ovnv1.OVNNorthdAutoscalingStatus{
+				NThreads: 3, ScaleDownTime: &start,
+			},
+			collectionTime: at(ScaleDownStabilization),
+			wantNThreads:   3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			nThreads, scaleDownTime := sizeNThreads(tt.sbCells, tt.previous, tt.collectionTime)
+			g.Expect(nThreads).To(Equal(tt.wantNThreads))
+			g.Expect(scaleDownTime).To(Equal(tt.wantScaleDownTime))
+		})
+	}
+}
+
+func TestGetAutoscalingStatus(t *testing.T) {
+	start := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	instance := &ovnv1.OVNNorthd{
+		Spec: ovnv1.OVNNorthdSpec{
+			OVNNorthdSpecCore: ovnv1.OVNNorthdSpecCore{
+				Autoscaling: ovnv1.OVNNorthdAutoscaling{
+					Mode:        ovnv1.OVNNorthdAutoscalingAuto,
+					MinNThreads: ptr.To[int32](1),
+					MaxNThreads: ptr.To[int32](4),
+				},
+			},
+		},
+	}
+
+	t.Run("bounds the number of threads", func(t *testing.T) {
+		g := NewWithT(t)
+		status := GetAutoscalingStatus(instance, "ovn-northd-0", 5000000, resource.MustParse("1Gi"), start)
+		g.Expect(status.NThreads).To(Equal(int32(4)))
+		g.Expect(status.Applied).To(BeTrue())
+		cpu := status.Requests[corev1.ResourceCPU]
+		g.Expect(cpu.MilliValue()).To(Equal(int64(4 * CPUPerThreadMillis)))
+	})
+
+	t.Run("keeps the memory request while the sized one is close", func(t *testing.T) {
+		g := NewWithT(t)
+		memoryRequest := func(status *ovnv1.OVNNorthdAutoscalingStatus) int64 {
+			request := status.Requests[corev1.ResourceMemory]
+			return request.Value()
+		}
+		instance := instance.DeepCopy()
+		instance.Status.Autoscaling = GetAutoscalingStatus(instance, "ovn-northd-0", 1000000, resource.MustParse("800Mi"), start)
+		g.Expect(memoryRequest(instance.Status.Autoscaling)).To(Equal(int64(1000 * 1024 * 1024)))
+
+		// 880Mi plus headroom is within the hysteresis of the 1000Mi request
+		status := GetAutoscalingStatus(instance, "ovn-northd-0", 1000000, resource.MustParse("880Mi"), start)
+		g.Expect(memoryRequest(status)).To(Equal(int64(1000 * 1024 * 1024)))
+
+		status = GetAutoscalingStatus(instance, "ovn-northd-0", 1000000, resource.MustParse("1Gi"), start)
+		g.Expect(memoryRequest(status)).To(Equal(int64(1280 * 1024 * 1024)))
+	})
+
+	t.Run("removes threads once the scale down window passed", func(t *testing.T) {
+		g := NewWithT(t)
+		instance := instance.DeepCopy()
+		instance.Status.Autoscaling = GetAutoscalingStatus(instance, "ovn-northd-0", 1500000, resource.MustParse("1Gi"), start)
+		g.Expect(instance.Status.Autoscaling.NThreads).To(Equal(int32(3)))
+
+		for _, minutes := range []time.Duration{0, 5, 10} {
+			instance.Status.Autoscaling = GetAutoscalingStatus(instance, "ovn-northd-0", 800000,
+				resource.MustParse("1Gi"), metav1.NewTime(start.Add(AutoscalingInterval+minutes*time.Minute)))
+			g.Expect(instance.Status.Autoscaling.NThreads).To(Equal(int32(3)))
+			g.Expect(instance.Status.Autoscaling.ScaleDownTime).NotTo(BeNil())
+		}
+
+		instance.Status.Autoscaling = GetAutoscalingStatus(instance, "ovn-northd-0", 800000,
+			resource.MustParse("1Gi"), metav1.NewTime(start.Add(AutoscalingInterval+ScaleDownStabilization)))
+		g.Expect(instance.Status.Autoscaling.NThreads).To(Equal(int32(2)))
+		g.Expect(instance.Status.Autoscaling.ScaleDownTime).To(BeNil())
+	})
+}
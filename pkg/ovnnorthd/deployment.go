@@ -36,29 +36,21 @@ const (
 // Deployment func
 func Deployment(
 	instance *ovnv1.OVNNorthd,
+	configHash string,
 	labels map[string]string,
 	nbEndpoint string,
 	sbEndpoint string,
 	envVars map[string]env.Setter,
+	containerImage string,
 ) *appsv1.Deployment {
 
-	livenessProbe := &corev1.Probe{
-		// TODO might need tuning
-		TimeoutSeconds:      5,
-		PeriodSeconds:       3,
-		InitialDelaySeconds: 3,
-	}
-	readinessProbe := &corev1.Probe{
-		// TODO might need tuning
-		TimeoutSeconds:      5,
-		PeriodSeconds:       5,
-		InitialDelaySeconds: 5,
-	}
+	livenessProbe := getProbe(instance.Spec.LivenessProbe, ovnv1.OVNNorthdLivenessProbeDefaults)
+	readinessProbe := getProbe(instance.Spec.ReadinessProbe, ovnv1.OVNNorthdReadinessProbeDefaults)
 	cmd := []string{ServiceCommand}
 	args := []string{
 		"-vfile:off",
 		fmt.Sprintf("-vconsole:%s", instance.Spec.LogLevel),
-		fmt.Sprintf("--n-threads=%d", *instance.Spec.NThreads),
+		fmt.Sprintf("--n-threads=%d", getNThreads(instance)),
 		fmt.Sprintf("--ovnnb-db=%s", nbEndpoint),
 		fmt.Sprintf("--ovnsb-db=%s", sbEndpoint),
 	}
@@ -94,15 +86,23 @@ func Deployment(
 	//
 	// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/
 	//
+	// ovn-appctl is served by the main loop of ovn-northd, it does not answer while stuck
 	livenessProbe.Exec = &corev1.ExecAction{
+		Command: AppctlCommand("status"),
+	}
+	// standby instances stay connected to the DBs, they are ready to take the SB lock over
+	readinessProbe.Exec = &corev1.ExecAction{
 		Command: []string{
-			"/usr/bin/pidof", "ovn-northd",
+			"/bin/bash", "-c",
+			appctl + " status > /dev/null && for db in nb sb; do " +
+				"status=$(" + appctl + " ${db}-connection-status) && [ \"${status}\" == connected ] || " +
+				"{ echo \"${db}: ${status}\"; exit 1; }; done",
 		},
 	}
-	readinessProbe.Exec = livenessProbe.Exec
 
 	// TODO: Make confs customizable
 	envVars["OVN_RUNDIR"] = env.SetValue("/tmp")
+	envVars["CONFIG_HASH"] = env.SetValue(configHash)
 
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -125,10 +125,10 @@ func Deployment(
 							Name:                     ovnv1.ServiceNameOVNNorthd,
 							Command:                  cmd,
 							Args:                     args,
-							Image:                    instance.Spec.ContainerImage,
+							Image:                    containerImage,
 							SecurityContext:          getOVNNorthdSecurityContext(),
 							Env:                      env.MergeEnvs([]corev1.EnvVar{}, envVars),
-							Resources:                instance.Spec.Resources,
+							Resources:                getResources(instance),
 							ReadinessProbe:           readinessProbe,
 							LivenessProbe:            livenessProbe,
 							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
@@ -156,3 +156,18 @@ func Deployment(
 
 	return deployment
 }
+
+// getProbe - probe with the timings of spec, unset ones taken from defaults
+func getProbe(probe *ovnv1.OVNNorthdProbe, defaults ovnv1.OVNNorthdProbe) *corev1.Probe {
+	spec := ovnv1.OVNNorthdProbe{}
+	if probe != nil {
+		spec = *probe.DeepCopy()
+	}
+	spec.Default(defaults)
+	return &corev1.Probe{
+		TimeoutSeconds:      *spec.TimeoutSeconds,
+		PeriodSeconds:       *spec.PeriodSeconds,
+		InitialDelaySeconds: *spec.InitialDelaySeconds,
+		FailureThreshold:    *spec.FailureThreshold,
+	}
+}
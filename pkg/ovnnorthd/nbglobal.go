@@ -0,0 +1,175 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovnnorthd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NBGlobalJobName - name of the Job applying the NB_Global options
+	NBGlobalJobName = ovnv1.ServiceNameOVNNorthd + "-nb-global"
+
+	// NBGlobalHash - Status.Hash key of the NB_Global options Job
+	NBGlobalHash = "nbglobal"
+
+	// NBGlobalCheckInterval - how often NB_Global is checked for drift once the options got applied
+	NBGlobalCheckInterval = 5 * time.Minute
+)
+
+// FormatOptions - sorted key=value entries of options
+func FormatOptions(options map[string]string) []string {
+	entries := []string{}
+	for key, value := range options {
+		entries = append(entries, key+"="+value)
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// NBGlobalJob - Job applying options to NB_Global, options no longer in the spec are removed
+func NBGlobalJob(
+	instance *ovnv1.OVNNorthd,
+	labels map[string]string,
+	nbEndpoint string,
+	options map[string]string,
+	envVars map[string]env.Setter,
+	containerImage string,
+) *batchv1.Job {
+	volumes := GetScriptsVolumes(instance.Name)
+	volumeMounts := GetScriptsVolumeMounts()
+
+	// add CA bundle if defined
+	if instance.Spec.TLS.CaBundleSecretName != "" {
+		volumes = append(volumes, instance.Spec.TLS.CreateVolume())
+		volumeMounts = append(volumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	// add OVN dbs cert and CA
+	if instance.Spec.TLS.Enabled() {
+		svc := tls.Service{
+			SecretName: *instance.Spec.TLS.GenericService.SecretName,
+			CertMount:  ptr.To(ovn_common.OVNDbCertPath),
+			KeyMount:   ptr.To(ovn_common.OVNDbKeyPath),
+			CaMount:    ptr.To(ovn_common.OVNDbCaCertPath),
+		}
+		volumes = append(volumes, svc.CreateVolume(ovnv1.ServiceNameOVNNorthd))
+		volumeMounts = append(volumeMounts, svc.CreateVolumeMounts(ovnv1.ServiceNameOVNNorthd)...)
+	}
+
+	envVars["NBRemote"] = env.SetValue(nbEndpoint)
+	envVars["NBGlobalOptions"] = env.SetValue(strings.Join(FormatOptions(options), " "))
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NBGlobalJobName,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					ServiceAccountName: instance.RbacResourceName(),
+					Containers: []corev1.Container{
+						{
+							Name:  "nb-global",
+							Image: containerImage,
+							Command: []string{
+								"/usr/local/bin/container-scripts/configure-nb-global.sh",
+							},
+							SecurityContext: getOVNNorthdSecurityContext(),
+							Env:             env.MergeEnvs([]corev1.EnvVar{}, envVars),
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					Volumes:      volumes,
+					NodeSelector: instance.Spec.NodeSelector,
+				},
+			},
+		},
+	}
+}
+
+// ctlCommand - ovn-nbctl or ovn-sbctl connecting to endpoint, with the certs of ovn-northd
+func ctlCommand(instance *ovnv1.OVNNorthd, ctl string, endpoint string) []string {
+	cmd := []string{ctl, "--db=" + endpoint}
+	if instance.Spec.TLS.Enabled() {
+		cmd = append(cmd,
+			"--private-key="+ovn_common.OVNDbKeyPath,
+			"--certificate="+ovn_common.OVNDbCertPath,
+			"--ca-cert="+ovn_common.OVNDbCaCertPath,
+		)
+	}
+	return cmd
+}
+
+// NBGlobalOptionsCommand - ovn-nbctl command listing the options of NB_Global
+func NBGlobalOptionsCommand(instance *ovnv1.OVNNorthd, nbEndpoint string) []string {
+	return append(ctlCommand(instance, "ovn-nbctl", nbEndpoint), "--bare", "--columns=options", "list", "NB_Global")
+}
+
+// ReadNBGlobalOptions - value in NB_Global of each of options, options missing there are
+// left out. NB_Global is read through a running ovn-northd pod, nothing is returned while
+// there is none.
+func ReadNBGlobalOptions(
+	ctx context.Context,
+	k8sClient client.Client,
+	executor ovn_common.PodExecutor,
+	instance *ovnv1.OVNNorthd,
+	nbEndpoint string,
+	options map[string]string,
+) (map[string]string, error) {
+	pods, err := GetPods(ctx, k8sClient, instance)
+	if err != nil || len(pods) == 0 {
+		return nil, err
+	}
+
+	output, err := executor.Exec(ctx, &pods[0], ovnv1.ServiceNameOVNNorthd, NBGlobalOptionsCommand(instance, nbEndpoint))
+	if err != nil {
+		return nil, err
+	}
+	applied := map[string]string{}
+	for _, entry := range strings.Fields(output) {
+		key, value, _ := strings.Cut(entry, "=")
+		if _, managed := options[key]; managed {
+			applied[key] = strings.Trim(value, "\"")
+		}
+	}
+	return applied, nil
+}
+
+// GetNBGlobalDrift - options which do not have their value in applied, with the value found there
+func GetNBGlobalDrift(options map[string]string, applied map[string]string) map[string]string {
+	drift := map[string]string{}
+	for key, value := range options {
+		if applied[key] != value {
+			drift[key] = applied[key]
+		}
+	}
+	return drift
+}
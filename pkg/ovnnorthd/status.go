@@ -0,0 +1,239 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovnnorthd
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// InstanceActive - the ovn-northd instance holds the SB lock and computes the SB DB
+	InstanceActive = "active"
+	// InstanceStandby - the ovn-northd instance waits for the SB lock
+	InstanceStandby = "standby"
+	// InstancePaused - the ovn-northd instance got paused with ovn-appctl pause
+	InstancePaused = "paused"
+	// InstanceUnknown - the ovn-northd instance could not be queried
+	InstanceUnknown = "unknown"
+
+	// InstancesCheckInterval - how often the state of the ovn-northd instances is queried
+	// while their pods do not change
+	InstancesCheckInterval = 60 * time.Second
+	// InstancesRetryInterval - how often the state of the ovn-northd instances is queried
+	// while they do not match spec.paused or could not be queried
+	InstancesRetryInterval = 5 * time.Second
+)
+
+// appctl - ovn-appctl targeting ovn-northd. ovn-northd runs without pidfile, its control
+// socket in OVN_RUNDIR is found through its pid.
+const appctl = "ovn-appctl -T 5 -t /tmp/ovn-northd.$(pidof ovn-northd).ctl"
+
+// AppctlCommand - ovn-appctl command run in the ovn-northd container
+func AppctlCommand(args ...string) []string {
+	return []string{"/bin/bash", "-c", appctl + " " + strings.Join(args, " ")}
+}
+
+// GetPods - running ovn-northd pods of the namespace of instance, sorted by name
+func GetPods(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNNorthd,
+) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	err := k8sClient.List(ctx, podList, client.InNamespace(instance.Namespace),
+		client.MatchingLabels{common.AppSelector: ovnv1.ServiceNameOVNNorthd})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := []corev1.Pod{}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	return pods, nil
+}
+
+// GetInstances - state of the ovn-northd instance of each running pod, as reported by
+// ovn-appctl status, keyed by pod name
+func GetInstances(
+	ctx context.Context,
+	k8sClient client.Client,
+	executor ovn_common.PodExecutor,
+	instance *ovnv1.OVNNorthd,
+) (map[string]string, error) {
+	pods, err := GetPods(ctx, k8sClient, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := map[string]string{}
+	for i := range pods {
+		instances[pods[i].Name] = getInstanceStatus(ctx, executor, &pods[i])
+	}
+
+	return instances, nil
+}
+
+// GetInstancesCheckInterval - time between two queries of the ovn-northd instances,
+// InstancesRetryInterval while some of them did not reach the state spec.paused asks
+// for or could not be queried at the last check
+func GetInstancesCheckInterval(instance *ovnv1.OVNNorthd) time.Duration {
+	if len(GetUnknownInstances(instance.Status.Instances)) > 0 ||
+		len(GetMismatchedInstances(instance.Status.Instances, instance.Spec.Paused)) > 0 {
+		return InstancesRetryInterval
+	}
+	return InstancesCheckInterval
+}
+
+// GetInstancesCheckWait - time until the ovn-northd instances have to be queried again,
+// zero when they have to be queried now. The running pods changing since the last check
+// makes it due right away.
+func GetInstancesCheckWait(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *ovnv1.OVNNorthd,
+	now time.Time,
+) (time.Duration, error) {
+	lastCheck := instance.Status.InstancesCheckTime
+	if lastCheck == nil {
+		return 0, nil
+	}
+	wait := lastCheck.Add(GetInstancesCheckInterval(instance)).Sub(now)
+	if wait <= 0 {
+		return 0, nil
+	}
+
+	pods, err := GetPods(ctx, k8sClient, instance)
+	if err != nil {
+		return 0, err
+	}
+	if len(pods) != len(instance.Status.Instances) {
+		return 0, nil
+	}
+	for _, pod := range pods {
+		if _, found := instance.Status.Instances[pod.Name]; !found {
+			return 0, nil
+		}
+	}
+
+	return wait, nil
+}
+
+// getInstanceStatus - parses the "Status: <state>" output of ovn-appctl status
+func getInstanceStatus(ctx context.Context, executor ovn_common.PodExecutor, pod *corev1.Pod) string {
+	output, err := executor.Exec(ctx, pod, ovnv1.ServiceNameOVNNorthd, AppctlCommand("status"))
+	if err != nil {
+		return InstanceUnknown
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if state, found := strings.CutPrefix(strings.TrimSpace(line), "Status:"); found {
+			switch state = strings.TrimSpace(state); state {
+			case InstanceActive, InstanceStandby, InstancePaused:
+				return state
+			}
+		}
+	}
+	return InstanceUnknown
+}
+
+// GetMismatchedInstances - sorted pods of instances which report being active or standby
+// while paused is set, or being paused while it is not. Instances which could not be
+// queried are left out, GetUnknownInstances reports them.
+func GetMismatchedInstances(instances map[string]string, paused bool) []string {
+	mismatched := []string{}
+	for pod, state := range instances {
+		if paused && (state == InstanceActive || state == InstanceStandby) ||
+			!paused && state == InstancePaused {
+			mismatched = append(mismatched, pod)
+		}
+	}
+	sort.Strings(mismatched)
+	return mismatched
+}
+
+// GetUnknownInstances - sorted pods of instances which could not be queried
+func GetUnknownInstances(instances map[string]string) []string {
+	unknown := []string{}
+	for pod, state := range instances {
+		if state == InstanceUnknown {
+			unknown = append(unknown, pod)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// PauseInstances - runs ovn-appctl pause in the pods of the instances which are not paused
+// while spec.paused is set, and ovn-appctl resume in the ones which are paused while it is
+// not. Returns whether a command ran, the caller queries the instances again to verify it.
+func PauseInstances(
+	ctx context.Context,
+	k8sClient client.Client,
+	executor ovn_common.PodExecutor,
+	instance *ovnv1.OVNNorthd,
+	instances map[string]string,
+) (bool, error) {
+	mismatched := GetMismatchedInstances(instances, instance.Spec.Paused)
+	if len(mismatched) == 0 {
+		return false, nil
+	}
+
+	pods, err := GetPods(ctx, k8sClient, instance)
+	if err != nil {
+		return false, err
+	}
+	command := "resume"
+	if instance.Spec.Paused {
+		command = "pause"
+	}
+	errs := []error{}
+	for i := range pods {
+		if !slices.Contains(mismatched, pods[i].Name) {
+			continue
+		}
+		_, err := executor.Exec(ctx, &pods[i], ovnv1.ServiceNameOVNNorthd, AppctlCommand(command))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return true, errors.Join(errs...)
+}
+
+// GetActiveInstances - sorted pods of instances which claim to hold the SB lock
+func GetActiveInstances(instances map[string]string) []string {
+	active := []string{}
+	for pod, state := range instances {
+		if state == InstanceActive {
+			active = append(active, pod)
+		}
+	}
+	sort.Strings(active)
+	return active
+}
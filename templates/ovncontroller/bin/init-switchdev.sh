@@ -0,0 +1,32 @@
+#!/bin/bash
+#
+# Copyright 2024 Red Hat Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License"); you may
+# not use this file except in compliance with the License. You may obtain
+# a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+# WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+# License for the specific language governing permissions and limitations
+# under the License.
+
+set -ex
+
+# The PFs live in the host network namespace, the ovs pods are not hostNetwork.
+HOST_NS="nsenter --mount=/host/proc/1/ns/mnt --net=/host/proc/1/ns/net"
+
+for pf in ${OVSSwitchdevPFs}; do
+    pci=$(${HOST_NS} ethtool -i ${pf} | awk '/^bus-info:/ {print $2}')
+    if [ -z "${pci}" ]; then
+        echo "Unable to find the PCI address of ${pf}"
+        exit 1
+    fi
+    if [ "$(${HOST_NS} devlink dev eswitch show pci/${pci} | grep -o 'mode [a-z]*' | cut -d' ' -f2)" != "switchdev" ]; then
+        ${HOST_NS} devlink dev eswitch set pci/${pci} mode switchdev
+    fi
+    ${HOST_NS} ethtool -K ${pf} hw-tc-offload on
+done
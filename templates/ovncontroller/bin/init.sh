@@ -21,5 +21,9 @@ wait_for_ovsdb_server
 # From now on, we should exit immediatelly when any command exits with non-zero status
 set -ex
 
+configure_dpdk
 configure_external_ids
+configure_extra_external_ids
+configure_wait_before_clear
 configure_physical_networks
+configure_datapath_type
@@ -0,0 +1,73 @@
+#!/bin/bash
+#
+# Copyright 2024 Red Hat Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License"); you may
+# not use this file except in compliance with the License. You may obtain
+# a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+# WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+# License for the specific language governing permissions and limitations
+# under the License.
+
+# Probe of the ovn-controller container, $1 is liveness or readiness.
+#
+# liveness: the ovn-controller process of the pidfile is running and its main
+# loop answers within LivenessTimeout seconds. It does not answer while it
+# recomputes the flows of a big deployment, the probe tolerates failures for
+# longer than that.
+# readiness: ovn-controller is connected to the SB DB and br-int got its flows.
+# The outcome is reported as the ovn.openstack.org/health annotation of the pod,
+# together with the downtime of the last graceful restart and the version of the
+# running ovn-controller.
+source $(dirname $0)/functions
+
+ProbeTimeout=${ProbeTimeout:-5}
+LivenessTimeout=${LivenessTimeout:-10}
+
+if [ "$1" == "liveness" ]; then
+    pid=$(cat /var/run/ovn/ovn-controller.pid 2>/dev/null)
+    if [ -z "${pid}" ] || ! kill -0 ${pid} 2>/dev/null; then
+        echo "ovn-controller is not running"
+        exit 1
+    fi
+    # a stuck main loop does not answer any command
+    if ! out=$(ovn-appctl -T ${LivenessTimeout} -t ovn-controller version 2>&1); then
+        echo "ovn-controller main loop not responding: ${out}"
+        exit 1
+    fi
+    exit 0
+fi
+
+if ! status=$(ovn-appctl -T ${ProbeTimeout} -t ovn-controller connection-status 2>&1); then
+    health="ovn-controller not responding: ${status}"
+elif [ "${status}" != "connected" ]; then
+    health="not connected to the SB DB: ${status}"
+else
+    bridge=$(ovs-vsctl --timeout=${ProbeTimeout} --if-exists get open . external_ids:ovn-bridge | tr -d '"')
+    flows=$(ovs-ofctl -t ${ProbeTimeout} dump-aggregate ${bridge:-br-int} 2>/dev/null | sed -n 's/.*flow_count=\([0-9]*\).*/\1/p')
+    if [ "${flows:-0}" -eq 0 ]; then
+        health="no flows on ${bridge:-br-int}"
+    fi
+fi
+
+annotate_pod "${OVNPodName}" ovn.openstack.org/health "${health:-ok}"
+# the operator holds the updates of ovn-northd and the DBs until every node
+# runs the new ovn-controller, so ask the running process for its version
+if version=$(ovn-appctl -T ${ProbeTimeout} -t ovn-controller version 2>/dev/null); then
+    annotate_pod "${OVNPodName}" ovn.openstack.org/ovn-version "$(echo "${version}" | awk 'NR==1 {print $NF}')"
+fi
+# the previous ovn-controller exited gracefully, report how long it took to be ready again
+if [ -z "${health}" ] && [ -f ${OVN_RESTART_TIME} ]; then
+    annotate_pod "${OVNPodName}" ovn.openstack.org/restart-downtime \
+        "$(( $(date +%s%3N) - $(cat ${OVN_RESTART_TIME}) ))"
+    rm -f ${OVN_RESTART_TIME}
+fi
+if [ -n "${health}" ]; then
+    echo "${health}"
+    exit 1
+fi
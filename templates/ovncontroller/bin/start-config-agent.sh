@@ -0,0 +1,46 @@
+#!/bin/bash
+#
+# Copyright 2024 Red Hat Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License"); you may
+# not use this file except in compliance with the License. You may obtain
+# a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+# WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+# License for the specific language governing permissions and limitations
+# under the License.
+
+source $(dirname $0)/functions
+
+# Seconds between two runs. Every run checks the whole config, so manual
+# changes of the settings managed by the operator get corrected. Only the
+# values which differ get written to OVS.
+AgentInterval=${AgentInterval:-30}
+
+wait_for_ovsdb_server
+
+applied=""
+while true; do
+    # the operator rolls new generations out node by node
+    generation=$(cat ${CONFIG_DIR}/node.${OVNHostName} 2>/dev/null || cat ${CONFIG_DIR}/generation 2>/dev/null)
+    if [ -z "${generation}" ] || [ ! -f ${CONFIG_DIR}/${generation}.env ]; then
+        echo "Waiting for the config to apply"
+    elif output=$(load_config ${CONFIG_DIR}/${generation}.env && $(dirname $0)/init.sh 2>&1); then
+        if [ "${applied}" != "${generation}" ]; then
+            echo "${output}"
+            echo "Applied config generation ${generation}"
+            applied=${generation}
+        fi
+        annotate_ovn_controller_pod ovn.openstack.org/config-generation "${generation}"
+        annotate_ovn_controller_pod ovn.openstack.org/config-error ""
+    else
+        echo "${output}"
+        echo "Failed to apply config generation ${generation}"
+        annotate_ovn_controller_pod ovn.openstack.org/config-error "${generation}: $(echo "${output}" | tail -1)"
+    fi
+    sleep ${AgentInterval}
+done
@@ -21,9 +21,23 @@ wait_for_ovsdb_server
 # wait_for_ovsdb_server interrim check would make the script exit.
 set -ex
 
-# Configure encap IP.
-OVNEncapIP=$(ip -o addr show dev {{ .OVNEncapNIC }} scope global | awk '{print $4}' | cut -d/ -f1)
-ovs-vsctl --no-wait set open . external-ids:ovn-encap-ip=${OVNEncapIP}
+# Configure encap IPs, the one of the first NIC is the default.
+OVNEncapIPs=""
+for nic in {{ .OVNEncapNICs }}; do
+    OVNEncapIP=$(ip -o addr show dev ${nic} scope global | awk '{print $4}' | cut -d/ -f1 | head -1)
+    OVNEncapIPs="${OVNEncapIPs:+${OVNEncapIPs},}${OVNEncapIP}"
+done
+ovs-vsctl --no-wait set open . external-ids:ovn-encap-ip="\"${OVNEncapIPs}\""
+if [[ "${OVNEncapIPs}" == *,* ]]; then
+    ovs-vsctl --no-wait set open . external-ids:ovn-encap-ip-default=${OVNEncapIPs%%,*}
+else
+    ovs-vsctl --no-wait --if-exists remove open . external_ids ovn-encap-ip-default
+fi
+
+# DPDK and hardware offload are initialized when vswitchd starts, so they have
+# to be configured first.
+configure_dpdk --no-wait
+configure_hw_offload --no-wait
 
 # Before starting vswitchd, block it from flushing existing datapath flows.
 ovs-vsctl --no-wait set open_vswitch . other_config:flow-restore-wait=true
@@ -32,13 +46,14 @@ ovs-vsctl --no-wait set open_vswitch . other_config:flow-restore-wait=true
 # --detach to allow the execution to continue to restoring the flows.
 /usr/sbin/ovs-vswitchd --pidfile --mlockall --detach
 
-# Restore saved flows.
+# Restore saved flows, the outcome is reported to the operator.
 if [ -f $FLOWS_RESTORE_SCRIPT ]; then
     # It's unsafe to leave these files in place if they fail once. Make sure we
     # remove them if the eval fails.
-    trap cleanup_flows_backup EXIT
+    trap 'report_flow_restore Failed $(count_flows $(ovs-vsctl -- --real list-br)); cleanup_flows_backup' EXIT
     eval "$(cat $FLOWS_RESTORE_SCRIPT)"
     trap - EXIT
+    report_flow_restore Succeeded $(count_flows $(ovs-vsctl -- --real list-br))
 fi
 
 # It's also unsafe to leave these files after flow-restore-wait flag is removed
@@ -50,5 +65,11 @@ cleanup_flows_backup
 ovs-vsctl remove open_vswitch . other_config flow-restore-wait
 
 # This is container command script. Block it from exiting, otherwise k8s will
-# restart the container again.
+# restart the container again. With hardware offload the offload state is
+# reported meanwhile.
+set +ex
+while [ "${OVSHWOffload}" == "true" ]; do
+    report_hw_offload
+    sleep 60
+done
 sleep infinity
@@ -0,0 +1,26 @@
+#!/bin/bash
+#
+# Copyright 2024 Red Hat Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License"); you may
+# not use this file except in compliance with the License. You may obtain
+# a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+# WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+# License for the specific language governing permissions and limitations
+# under the License.
+
+set -ex
+source $(dirname $0)/functions
+
+# The readiness probe of the next ovn-controller reports the restart downtime
+# from this time
+date +%s%3N > $OVN_RESTART_TIME
+
+# Exit without clearing the flows, nor removing the chassis from the SB DB,
+# the next ovn-controller replaces the flows once it computed them.
+ovn-appctl -t ovn-controller exit --restart
@@ -27,6 +27,7 @@ bridges=$(ovs-vsctl -- --real list-br)
 # Saving flows to avoid disrupting gateway datapath.
 mkdir $FLOWS_RESTORE_DIR
 TMPDIR=$FLOWS_RESTORE_DIR /usr/share/openvswitch/scripts/ovs-save save-flows $bridges > $FLOWS_RESTORE_SCRIPT
+count_flows $bridges > $FLOWS_SAVED_COUNT
 
 # Once save-flows logic is complete it no longer needs ovsdb-server, this file
 # unlocks the db preStop script, working as a semaphore
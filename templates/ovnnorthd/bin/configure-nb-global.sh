@@ -0,0 +1,48 @@
+#!/bin/bash
+#
+# Copyright 2024 Red Hat Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License"); you may
+# not use this file except in compliance with the License. You may obtain
+# a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+# WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+# License for the specific language governing permissions and limitations
+# under the License.
+
+set -ex
+
+{{- if .TLS }}
+NBCTL="ovn-nbctl --db=${NBRemote} --private-key={{ .OVNDB_KEY_PATH }} --certificate={{ .OVNDB_CERT_PATH }} --ca-cert={{ .OVNDB_CACERT_PATH }}"
+{{- else }}
+NBCTL="ovn-nbctl --db=${NBRemote}"
+{{- end }}
+
+# Returns the set difference between $1 and $2
+function set_difference {
+    echo "$(comm -23 <(sort -u <(echo $1 | xargs -n1)) <(sort -u <(echo $2 | xargs -n1)))"
+}
+
+# The options set by the operator are tracked in external_ids, so that those
+# removed from the spec get removed from NB_Global, and the options set by
+# other clients are left alone.
+applied=$(${NBCTL} --if-exists get NB_Global . external_ids:ovn-operator-options | tr -d '"')
+keys=""
+for entry in ${NBGlobalOptions}; do
+    keys="${keys} ${entry%%=*}"
+    ${NBCTL} set NB_Global . options:${entry%%=*}="\"${entry#*=}\""
+done
+for key in $(set_difference "${applied}" "${keys}"); do
+    ${NBCTL} --if-exists remove NB_Global . options ${key}
+done
+
+keys=$(echo ${keys})
+if [ -n "${keys}" ]; then
+    ${NBCTL} set NB_Global . external_ids:ovn-operator-options="\"${keys}\""
+else
+    ${NBCTL} --if-exists remove NB_Global . external_ids ovn-operator-options
+fi
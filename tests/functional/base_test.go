@@ -18,13 +18,17 @@ limitations under the License.
 package functional_test
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/onsi/gomega" //revive:disable:dot-imports
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
@@ -35,6 +39,7 @@ import (
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
 )
 
 const (
@@ -193,6 +198,16 @@ func GetDaemonSet(name types.NamespacedName) *appsv1.DaemonSet {
 	return ds
 }
 
+// GetHostPath - returns the hostPath of the named volume
+func GetHostPath(volumes []corev1.Volume, name string) string {
+	for _, v := range volumes {
+		if v.Name == name && v.HostPath != nil {
+			return v.HostPath.Path
+		}
+	}
+	return ""
+}
+
 // ListDaemonsets -
 func ListDaemonsets(namespace string) *appsv1.DaemonSetList {
 	dss := &appsv1.DaemonSetList{}
@@ -347,6 +362,146 @@ func CheckDNSDataContainsIP(namespace string, dnsEntryName string, ip string) bo
 	return false
 }
 
+// CreateOVSPod - creates an ovs pod of the OVNController name on node
+func CreateOVSPod(name types.NamespacedName, node string) *corev1.Pod {
+	ds := GetDaemonSet(types.NamespacedName{Namespace: name.Namespace, Name: "ovn-controller-ovs"})
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      "ovn-controller-ovs-" + node,
+			Labels: map[string]string{
+				"service": "ovn-controller-ovs",
+			},
+		},
+		Spec: ds.Spec.Template.Spec,
+	}
+	pod.Spec.NodeName = node
+	Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
+
+	return pod
+}
+
+// AnnotateOVSPod - sets an annotation on the ovs pod of node, as the OVS scripts do
+func AnnotateOVSPod(namespace string, node string, key string, value string) {
+	AnnotatePod(types.NamespacedName{Namespace: namespace, Name: "ovn-controller-ovs-" + node}, key, value)
+}
+
+// CreateOVNControllerPod - creates the ovn-controller pod of node, which runs the config agent
+func CreateOVNControllerPod(name types.NamespacedName, node string) *corev1.Pod {
+	ds := GetDaemonSet(types.NamespacedName{Namespace: name.Namespace, Name: "ovn-controller"})
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      "ovn-controller-" + node,
+			Labels: map[string]string{
+				"service": "ovn-controller",
+			},
+		},
+		Spec: ds.Spec.Template.Spec,
+	}
+	pod.Spec.NodeName = node
+	Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
+
+	return pod
+}
+
+// AnnotateOVNControllerPod - sets an annotation on the ovn-controller pod of node, as the agent does
+func AnnotateOVNControllerPod(namespace string, node string, key string, value string) {
+	AnnotatePod(types.NamespacedName{Namespace: namespace, Name: "ovn-controller-" + node}, key, value)
+}
+
+// AnnotatePod - sets an annotation on the pod name
+func AnnotatePod(name types.NamespacedName, key string, value string) {
+	Eventually(func(g Gomega) {
+		pod := &corev1.Pod{}
+		g.Expect(k8sClient.Get(ctx, name, pod)).Should(Succeed())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[key] = value
+		g.Expect(k8sClient.Update(ctx, pod)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+// CreateNode - creates a node with labels
+func CreateNode(name string, labels map[string]string) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+	Expect(k8sClient.Create(ctx, node)).Should(Succeed())
+
+	return node
+}
+
+// CreateReadyDaemonSetPod - creates the pod of the DaemonSet name on node from its current
+// template, as the DaemonSet controller does, and marks it as ready
+func CreateReadyDaemonSetPod(name types.NamespacedName, node string) *corev1.Pod {
+	ds := GetDaemonSet(name)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name + "-" + node,
+			Labels:    ds.Spec.Template.Labels,
+		},
+		Spec: ds.Spec.Template.Spec,
+	}
+	pod.Spec.NodeName = node
+	Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
+
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, pod)).Should(Succeed())
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodReady,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+		}}
+		g.Expect(k8sClient.Status().Update(ctx, pod)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+
+	return pod
+}
+
+// ReplaceDaemonSetPod - removes the pod of the DaemonSet name on node and creates it again
+// from the current template of the DaemonSet
+func ReplaceDaemonSetPod(name types.NamespacedName, node string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name + "-" + node,
+		},
+	}
+	th.DeleteInstance(pod, client.GracePeriodSeconds(0))
+
+	return CreateReadyDaemonSetPod(name, node)
+}
+
+// IsPodDeleted - whether the pod of the DaemonSet name on node got deleted
+func IsPodDeleted(name types.NamespacedName, node string) bool {
+	pod := &corev1.Pod{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Namespace: name.Namespace, Name: name.Name + "-" + node}, pod)
+	if k8s_errors.IsNotFound(err) {
+		return true
+	}
+	Expect(err).ShouldNot(HaveOccurred())
+	return pod.DeletionTimestamp != nil
+}
+
+// GetAgentConfig - returns the settings of the current generation of the
+// agent config of the OVNController name
+func GetAgentConfig(name types.NamespacedName) map[string]string {
+	cm := th.GetConfigMap(types.NamespacedName{Namespace: name.Namespace, Name: name.Name + "-agent-config"})
+	config := map[string]string{}
+	for _, line := range strings.Split(cm.Data[cm.Data["generation"]+".env"], "\n") {
+		if key, value, found := strings.Cut(line, "="); found {
+			config[key] = value
+		}
+	}
+	return config
+}
+
 func GetPod(name types.NamespacedName) *corev1.Pod {
 	pod := &corev1.Pod{}
 	Eventually(func(g Gomega) {
@@ -381,3 +536,123 @@ func GetServicesListWithLabel(namespace string, labelSelectorMap ...map[string]s
 
 	return serviceList
 }
+
+// FakePodExecutor - runs no command, answers with the outputs set by the tests as envtest does not run pods
+type FakePodExecutor struct {
+	mu      sync.Mutex
+	outputs map[string]string
+}
+
+// NewFakePodExecutor - FakePodExecutor without any output set
+func NewFakePodExecutor() *FakePodExecutor {
+	return &FakePodExecutor{outputs: map[string]string{}}
+}
+
+func fakeExecKey(pod types.NamespacedName, command []string) string {
+	return pod.String() + " " + strings.Join(command, " ")
+}
+
+// SetOutput - output of command in pod, the command fails while no output is set
+func (e *FakePodExecutor) SetOutput(pod types.NamespacedName, command []string, output string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.outputs[fakeExecKey(pod, command)] = output
+}
+
+// RemoveOutput - makes command in pod fail again
+func (e *FakePodExecutor) RemoveOutput(pod types.NamespacedName, command []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.outputs, fakeExecKey(pod, command))
+}
+
+// Exec - returns the output set for command in pod
+func (e *FakePodExecutor) Exec(_ context.Context, pod *corev1.Pod, _ string, command []string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	output, ok := e.outputs[fakeExecKey(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, command)]
+	if !ok {
+		return "", fmt.Errorf("%s in pod %s failed", strings.Join(command, " "), pod.Name)
+	}
+	return output, nil
+}
+
+// CreateRunningPod - creates a running pod with labels, its single container is named container
+func CreateRunningPod(name types.NamespacedName, labels map[string]string, container string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: container, Image: "test"}},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
+	pod.Status.Phase = corev1.PodRunning
+	Expect(k8sClient.Status().Update(ctx, pod)).Should(Succeed())
+	return pod
+}
+
+// SimulatePodRunning - sets the phase of an existing pod to running
+func SimulatePodRunning(name types.NamespacedName) {
+	Eventually(func(g Gomega) {
+		pod := GetPod(name)
+		pod.Status.Phase = corev1.PodRunning
+		g.Expect(k8sClient.Status().Update(ctx, pod)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+// CompleteOVNVersionJob - simulates the pod of the OVN version Job of owner for image printing version
+func CompleteOVNVersionJob(owner client.Object, image string, version string) *corev1.Pod {
+	name, err := ovn_common.OVNVersionJobName(owner, image)
+	Expect(err).ShouldNot(HaveOccurred())
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-pod",
+			Namespace: owner.GetNamespace(),
+			Labels:    map[string]string{"job-name": name},
+		},
+		Spec: corev1.PodSpec{
+			Containers:    []corev1.Container{{Name: "ovn-version", Image: image}},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
+	pod.Status.Phase = corev1.PodSucceeded
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name: "ovn-version",
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Message: version},
+		},
+	}}
+	Expect(k8sClient.Status().Update(ctx, pod)).Should(Succeed())
+	return pod
+}
+
+// CreateOVNDiagnostics - creates an OVNDiagnostics running commands against target
+func CreateOVNDiagnostics(namespace string, spec ovnv1.OVNDiagnosticsSpec) types.NamespacedName {
+	instance := &ovnv1.OVNDiagnostics{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ovndiagnostics",
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+	Expect(k8sClient.Create(ctx, instance)).Should(Succeed())
+	return types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}
+}
+
+func GetOVNDiagnostics(name types.NamespacedName) *ovnv1.OVNDiagnostics {
+	instance := &ovnv1.OVNDiagnostics{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func OVNDiagnosticsConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetOVNDiagnostics(name)
+	return instance.Status.Conditions
+}
@@ -19,6 +19,7 @@ package functional_test
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
 	. "github.com/onsi/gomega"    //revive:disable:dot-imports
@@ -30,9 +31,15 @@ import (
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
 	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
-	batchv1 "k8s.io/api/batch/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("OVNController controller", func() {
@@ -85,26 +92,15 @@ var _ = Describe("OVNController controller", func() {
 			)
 		})
 
-		It("should not create a config job", func() {
-			daemonSetName := types.NamespacedName{
-				Namespace: namespace,
-				Name:      "ovn-controller",
-			}
-			configJob := types.NamespacedName{
-				Namespace: OVNControllerName.Namespace,
-				Name:      daemonSetName.Name + "-config",
-			}
-			th.AssertJobDoesNotExist(configJob)
-
-			daemonSetNameOVS := types.NamespacedName{
-				Namespace: namespace,
-				Name:      "ovn-controller-ovs",
-			}
-			configJobOVS := types.NamespacedName{
+		It("should not render the agent config", func() {
+			agentCM := types.NamespacedName{
 				Namespace: OVNControllerName.Namespace,
-				Name:      daemonSetNameOVS.Name + "-config",
+				Name:      OVNControllerName.Name + "-agent-config",
 			}
-			th.AssertJobDoesNotExist(configJobOVS)
+			Consistently(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8s_errors.IsNotFound(k8sClient.Get(ctx, agentCM, cm))).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
 		})
 
 		// TODO(ihar) introduce a new condition for the external config?
@@ -139,7 +135,7 @@ var _ = Describe("OVNController controller", func() {
 				}
 			})
 
-			It("should create a config job for ovn-controller and not for ovn-controller-ovs", func() {
+			It("should render the agent config instead of config jobs", func() {
 				daemonSetName := types.NamespacedName{
 					Namespace: namespace,
 					Name:      "ovn-controller",
@@ -148,27 +144,87 @@ var _ = Describe("OVNController controller", func() {
 					daemonSetName,
 					map[string][]string{},
 				)
+				Eventually(func(g Gomega) {
+					config := GetAgentConfig(OVNControllerName)
+					g.Expect(config).To(HaveKeyWithValue("OVNBridge", "br-int"))
+					g.Expect(config).To(HaveKeyWithValue("OVNEncapType", "geneve"))
+					g.Expect(config).To(HaveKey("OVNRemote"))
+				}, timeout, interval).Should(Succeed())
+
 				configJob := types.NamespacedName{
 					Namespace: OVNControllerName.Namespace,
 					Name:      daemonSetName.Name + "-config",
 				}
-				Eventually(func() batchv1.Job {
-					return *th.GetJob(configJob)
-				}, timeout, interval).ShouldNot(BeNil())
+				th.AssertJobDoesNotExist(configJob)
+			})
 
+			It("should run the config agent in the ovn-controller pods", func() {
+				daemonSetName := types.NamespacedName{
+					Namespace: namespace,
+					Name:      "ovn-controller",
+				}
 				daemonSetNameOVS := types.NamespacedName{
 					Namespace: namespace,
 					Name:      "ovn-controller-ovs",
 				}
+				Eventually(func(g Gomega) {
+					podSpec := GetDaemonSet(daemonSetName).Spec.Template.Spec
+					g.Expect(podSpec.Containers).To(HaveLen(2))
+					g.Expect(podSpec.Containers[1].Name).To(Equal("ovn-config-agent"))
+					g.Expect(podSpec.Containers[1].Image).To(Equal(podSpec.Containers[0].Image))
+					volumeNames := []string{}
+					for _, volume := range podSpec.Volumes {
+						if volume.ConfigMap != nil {
+							volumeNames = append(volumeNames, volume.ConfigMap.Name)
+						}
+					}
+					g.Expect(volumeNames).To(ContainElement(OVNControllerName.Name + "-agent-config"))
+
+					// the OVS pods only run the OVS image
+					for _, container := range GetDaemonSet(daemonSetNameOVS).Spec.Template.Spec.Containers {
+						g.Expect(container.Name).ToNot(Equal("ovn-config-agent"))
+					}
+				}, timeout, interval).Should(Succeed())
+			})
+
+			It("does not label the pods with their template hash without image rollout", func() {
+				for _, name := range []string{"ovn-controller", "ovn-controller-ovs"} {
+					daemonSetName := types.NamespacedName{Namespace: namespace, Name: name}
+					Eventually(func(g Gomega) {
+						ds := GetDaemonSet(daemonSetName)
+						g.Expect(ds.Spec.Template.Labels).ToNot(HaveKey("ovn.openstack.org/template-hash"))
+						g.Expect(ds.Spec.UpdateStrategy.Type).ToNot(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+					}, timeout, interval).Should(Succeed())
+				}
+			})
+
+			It("reports the config generation applied on each node", func() {
+				daemonSetName := types.NamespacedName{
+					Namespace: namespace,
+					Name:      "ovn-controller",
+				}
 				SimulateDaemonsetNumberReadyWithPods(
-					daemonSetNameOVS,
+					daemonSetName,
 					map[string][]string{},
 				)
-				configJobOVS := types.NamespacedName{
-					Namespace: OVNControllerName.Namespace,
-					Name:      daemonSetNameOVS.Name + "-config",
+				var generation string
+				Eventually(func(g Gomega) {
+					generation = GetOVNController(OVNControllerName).Status.Hash[ovnv1.OVNConfigHash]
+					g.Expect(generation).ToNot(BeEmpty())
+				}, timeout, interval).Should(Succeed())
+
+				pod := GetPod(daemonSetName)
+				if pod.Annotations == nil {
+					pod.Annotations = map[string]string{}
 				}
-				th.AssertJobDoesNotExist(configJobOVS)
+				pod.Annotations["ovn.openstack.org/config-generation"] = generation
+				UpdatePod(pod)
+
+				Eventually(func(g Gomega) {
+					nodes := GetOVNController(OVNControllerName).Status.Nodes
+					g.Expect(nodes).To(HaveKey(pod.Spec.NodeName))
+					g.Expect(nodes[pod.Spec.NodeName].ConfigGeneration).To(Equal(generation))
+				}, timeout, interval).Should(Succeed())
 			})
 
 			It("should create a ConfigMap for start-vswitchd.sh with eth0 as Interface Name", func() {
@@ -217,22 +273,19 @@ var _ = Describe("OVNController controller", func() {
 				)
 			})
 
-			It("should create a config job", func() {
-				configJob := types.NamespacedName{
-					Namespace: OVNControllerName.Namespace,
-					Name:      daemonSetName.Name + "-config",
-				}
-				Eventually(func() batchv1.Job {
-					return *th.GetJob(configJob)
-				}, timeout, interval).ShouldNot(BeNil())
+			It("should render the agent config", func() {
+				Eventually(func(g Gomega) {
+					g.Expect(GetAgentConfig(OVNControllerName)).To(HaveKey("OVNRemote"))
+				}, timeout, interval).Should(Succeed())
 			})
 
-			It("should not create a config job", func() {
-				configJob := types.NamespacedName{
-					Namespace: OVNControllerName.Namespace,
-					Name:      daemonSetNameOVS.Name + "-config",
+			It("should not create config jobs", func() {
+				for _, name := range []string{daemonSetName.Name, daemonSetNameOVS.Name} {
+					th.AssertJobDoesNotExist(types.NamespacedName{
+						Namespace: OVNControllerName.Namespace,
+						Name:      name + "-config",
+					})
 				}
-				th.AssertJobDoesNotExist(configJob)
 			})
 
 		})
@@ -257,7 +310,7 @@ var _ = Describe("OVNController controller", func() {
 			DeferCleanup(th.DeleteInstance, instance)
 		})
 
-		It("should create a config job for ovn-controller and not for ovn-controller-ovs", func() {
+		It("should render the agent config and not create config jobs", func() {
 			daemonSetName := types.NamespacedName{
 				Namespace: namespace,
 				Name:      "ovn-controller",
@@ -275,18 +328,13 @@ var _ = Describe("OVNController controller", func() {
 				daemonSetNameOVS,
 				map[string][]string{namespace + "/internalapi": {"10.0.0.1"}},
 			)
-			configJob := types.NamespacedName{
+			Eventually(func(g Gomega) {
+				g.Expect(GetAgentConfig(OVNControllerName)).To(HaveKey("OVNRemote"))
+			}, timeout, interval).Should(Succeed())
+			th.AssertJobDoesNotExist(types.NamespacedName{
 				Namespace: OVNControllerName.Namespace,
 				Name:      daemonSetName.Name + "-config",
-			}
-			configJobOVS := types.NamespacedName{
-				Namespace: OVNControllerName.Namespace,
-				Name:      daemonSetNameOVS.Name + "-config",
-			}
-			Eventually(func() batchv1.Job {
-				return *th.GetJob(configJob)
-			}, timeout, interval).ShouldNot(BeNil())
-			th.AssertJobDoesNotExist(configJobOVS)
+			})
 		})
 		It("reports that network attachment is missing", func() {
 
@@ -518,6 +566,50 @@ var _ = Describe("OVNController controller", func() {
 			}, timeout, interval).Should(Succeed())
 		})
 
+		It("deletes the networkattachment definition of a removed nicMapping", func() {
+			nadName := types.NamespacedName{
+				Namespace: OVNControllerName.Namespace,
+				Name:      "physnet1",
+			}
+			Eventually(func(g Gomega) {
+				g.Expect(GetNAD(nadName).Spec.Config).Should(
+					ContainSubstring("enp2s0.100"))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(OVNControllerName)
+				ovnController.Spec.NicMappings = map[string]string{
+					"physnet2": "enp3s0.100",
+				}
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				nad := &networkv1.NetworkAttachmentDefinition{}
+				err := k8sClient.Get(ctx, nadName, nad)
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			expectedAnnotation, err := json.Marshal(
+				[]networkv1.NetworkSelectionElement{
+					{
+						Name:             "physnet2",
+						Namespace:        namespace,
+						InterfaceRequest: "physnet2",
+					},
+				})
+			Expect(err).ShouldNot(HaveOccurred())
+			Eventually(func(g Gomega) {
+				ds := GetDaemonSet(types.NamespacedName{
+					Namespace: namespace,
+					Name:      "ovn-controller-ovs",
+				})
+				g.Expect(ds.Spec.Template.ObjectMeta.Annotations).To(
+					HaveKeyWithValue("k8s.v1.cni.cncf.io/networks", string(expectedAnnotation)),
+				)
+			}, timeout, interval).Should(Succeed())
+		})
+
 		It("should not update the networkattachment definition created externally", func() {
 			nad := types.NamespacedName{
 				Namespace: OVNControllerName.Namespace,
@@ -684,6 +776,7 @@ var _ = Describe("OVNController controller", func() {
 			Expect(ovnController.Spec.ExternalIDS.OvnEncapType).To(Equal("geneve"))
 			Expect(ovnController.Spec.ExternalIDS.OvnBridge).To(Equal("br-int"))
 			Expect(ovnController.Spec.ExternalIDS.SystemID).To(Equal("random"))
+			Expect(ovnController.Spec.HostStateDir).To(Equal("/var/home/core"))
 		})
 	})
 
@@ -738,7 +831,7 @@ var _ = Describe("OVNController controller", func() {
 			)
 		})
 
-		It("OVS Daemonset is created with 3 containers including an init container", func() {
+		It("OVS Daemonset is created with 4 containers including an init container", func() {
 			DeferCleanup(k8sClient.Delete, ctx, th.CreateCABundleSecret(types.NamespacedName{
 				Name:      CABundleSecretName,
 				Namespace: namespace,
@@ -765,7 +858,7 @@ var _ = Describe("OVNController controller", func() {
 			ds := GetDaemonSet(daemonSetNameOVS)
 
 			Expect(ds.Spec.Template.Spec.InitContainers).To(HaveLen(1))
-			Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(2))
+			Expect(ds.Spec.Template.Spec.Containers).To(HaveLen(3))
 		})
 
 		It("creates a Daemonset with TLS certs attached", func() {
@@ -925,4 +1018,1120 @@ var _ = Describe("OVNController controller", func() {
 			}, timeout, interval).Should(Succeed())
 		})
 	})
+
+	When("OVNController is created with hostStateDir", func() {
+		var ovnControllerName types.NamespacedName
+		var daemonSetName types.NamespacedName
+		var daemonSetNameOVS types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.HostStateDir = "/var/lib/ovn-operator"
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+			daemonSetName = types.NamespacedName{
+				Namespace: namespace,
+				Name:      "ovn-controller",
+			}
+			daemonSetNameOVS = types.NamespacedName{
+				Namespace: namespace,
+				Name:      "ovn-controller-ovs",
+			}
+		})
+
+		It("mounts the OVS/OVN state from hostStateDir", func() {
+			Eventually(func(g Gomega) {
+				ds := GetDaemonSet(daemonSetName)
+				g.Expect(GetHostPath(ds.Spec.Template.Spec.Volumes, "var-run-ovn")).To(
+					Equal("/var/lib/ovn-operator/" + namespace + "/var/run/ovn"))
+				ovsDs := GetDaemonSet(daemonSetNameOVS)
+				g.Expect(GetHostPath(ovsDs.Spec.Template.Spec.Volumes, "etc-ovs")).To(
+					Equal("/var/lib/ovn-operator/" + namespace + "/etc/ovs"))
+			}, timeout, interval).Should(Succeed())
+
+			th.ExpectCondition(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerHostStateDirReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(GetOVNController(ovnControllerName).Status.HostStateDir).To(Equal("/var/lib/ovn-operator"))
+		})
+
+		It("refuses a hostStateDir change while pods are scheduled", func() {
+			SimulateDaemonsetNumberReady(daemonSetName)
+			SimulateDaemonsetNumberReady(daemonSetNameOVS)
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNController(ovnControllerName).Status.DesiredNumberScheduled).To(Equal(int32(1)))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.HostStateDir = "/var/lib/other"
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			th.ExpectConditionWithDetails(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerHostStateDirReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf(ovnv1.OVNControllerHostStateDirChangeRefusedMessage,
+					"/var/lib/ovn-operator", "/var/lib/other", 1),
+			)
+			th.ExpectCondition(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionFalse,
+			)
+
+			// the pods keep using the previous directory
+			ds := GetDaemonSet(daemonSetNameOVS)
+			Expect(GetHostPath(ds.Spec.Template.Spec.Volumes, "etc-ovs")).To(
+				Equal("/var/lib/ovn-operator/" + namespace + "/etc/ovs"))
+			Expect(GetOVNController(ovnControllerName).Status.HostStateDir).To(Equal("/var/lib/ovn-operator"))
+		})
+	})
+
+	When("OVNController is created with bridges", func() {
+		var ovnControllerName types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.NicMappings = map[string]string{
+				"physnet1": "enp2s0",
+			}
+			spec.Bridges = []ovnv1.OVSBridge{
+				{
+					Name:             "br-ex",
+					PhysicalNetworks: []string{"datacentre", "tenant"},
+					Uplink: &ovnv1.OVSBridgeUplink{
+						Interfaces: []string{"enp3s0", "enp4s0"},
+						BondMode:   "balance-tcp",
+						LACP:       "active",
+						Trunks:     []int32{100, 200},
+					},
+				},
+				{
+					Name:             "br-storage",
+					PhysicalNetworks: []string{"storage"},
+					Uplink: &ovnv1.OVSBridgeUplink{
+						Interfaces: []string{"enp5s0"},
+					},
+				},
+			}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+		})
+
+		It("creates a host-device network attachment for each uplink interface", func() {
+			for _, iface := range []string{"enp3s0", "enp4s0", "enp5s0"} {
+				nadName := types.NamespacedName{Namespace: namespace, Name: iface}
+				Eventually(func(g Gomega) {
+					nad := GetNAD(nadName)
+					g.Expect(nad.Spec.Config).To(ContainSubstring(`"type": "host-device"`))
+					g.Expect(nad.Spec.Config).To(ContainSubstring(fmt.Sprintf(`"device": "%s"`, iface)))
+					g.Expect(nad.ObjectMeta.OwnerReferences[0].Name).To(Equal(ovnControllerName.Name))
+				}, timeout, interval).Should(Succeed())
+			}
+		})
+
+		It("passes the bridges to the config agent", func() {
+			Eventually(func(g Gomega) {
+				config := GetAgentConfig(ovnControllerName)
+				g.Expect(config).To(HaveKeyWithValue("PhysicalNetworks", "physnet1"))
+				g.Expect(config).To(HaveKeyWithValue("OVSBridges",
+					"br-ex;datacentre,tenant;br-ex-bond;enp3s0,enp4s0;balance-tcp;active;100,200 "+
+						"br-storage;storage;enp5s0;enp5s0;;;"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the bridge drift of each node", func() {
+			daemonSetName := types.NamespacedName{
+				Namespace: namespace,
+				Name:      "ovn-controller",
+			}
+			SimulateDaemonsetNumberReadyWithPods(
+				daemonSetName,
+				map[string][]string{},
+			)
+			pod := GetPod(daemonSetName)
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations["ovn.openstack.org/bridge-drift"] =
+				"bridge br-storage missing;port br-ex-bond lacp is 'off' instead of 'active'"
+			UpdatePod(pod)
+
+			// Call reconcile loop, the drift must not depend on the pod watch
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				if ovnController.Annotations == nil {
+					ovnController.Annotations = map[string]string{}
+				}
+				// Change something just to call reconcile loop
+				ovnController.Annotations["test/reconcile"] = pod.Name
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				nodes := GetOVNController(ovnControllerName).Status.Nodes
+				g.Expect(nodes).To(HaveKey(pod.Spec.NodeName))
+				g.Expect(nodes[pod.Spec.NodeName].BridgeDrift).To(ConsistOf(
+					"bridge br-storage missing",
+					"port br-ex-bond lacp is 'off' instead of 'active'",
+				))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects a physical network mapped twice", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.Bridges[1].PhysicalNetworks = []string{"physnet1"}
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.bridges[1].physicalNetworks[0]: Duplicate value"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects an uplink interface named after a physical network", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.Bridges[1].Uplink.Interfaces = []string{"physnet1"}
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(
+					"NetworkAttachmentDefinition name already used by a physical network or network attachment"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController is created with nic mappings config", func() {
+		var ovnControllerName types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.NicMappings = map[string]string{
+				"physnet1": "enp2s0",
+				"physnet2": "bond0",
+				"physnet3": "enp3s0",
+				"physnet4": "vf",
+			}
+			spec.NicMappingsConfig = map[string]ovnv1.NicMappingConfig{
+				"physnet2": {
+					Type:     ovnv1.NicMappingTypeBond,
+					Members:  []string{"enp4s0", "enp5s0"},
+					BondMode: "802.3ad",
+				},
+				"physnet3": {
+					Type: ovnv1.NicMappingTypeMacvlan,
+				},
+				"physnet4": {
+					Type:         ovnv1.NicMappingTypeSRIOV,
+					ResourceName: "openshift.io/physnet4",
+				},
+			}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+		})
+
+		It("creates the network attachment definitions of each type", func() {
+			Eventually(func(g Gomega) {
+				nad := GetNAD(types.NamespacedName{Namespace: namespace, Name: "physnet1"})
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"type": "host-device"`))
+
+				nad = GetNAD(types.NamespacedName{Namespace: namespace, Name: "physnet2"})
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"type":"bond"`))
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"mode":"802.3ad"`))
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"links":[{"name":"enp4s0"},{"name":"enp5s0"}]`))
+
+				nad = GetNAD(types.NamespacedName{Namespace: namespace, Name: "physnet3"})
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"type":"macvlan"`))
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"master":"enp3s0"`))
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"mode":"passthru"`))
+
+				nad = GetNAD(types.NamespacedName{Namespace: namespace, Name: "physnet4"})
+				g.Expect(nad.Spec.Config).To(ContainSubstring(`"type":"sriov"`))
+				g.Expect(nad.Annotations).To(HaveKeyWithValue(
+					"k8s.v1.cni.cncf.io/resourceName", "openshift.io/physnet4"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("requests the SR-IOV resource for ovs-vswitchd", func() {
+			daemonSetNameOVS := types.NamespacedName{
+				Namespace: namespace,
+				Name:      "ovn-controller-ovs",
+			}
+			Eventually(func(g Gomega) {
+				vswitchd := GetDaemonSet(daemonSetNameOVS).Spec.Template.Spec.Containers[1]
+				g.Expect(vswitchd.Name).To(Equal("ovs-vswitchd"))
+				vfs := vswitchd.Resources.Requests[corev1.ResourceName("openshift.io/physnet4")]
+				g.Expect(vfs.String()).To(Equal("1"))
+				vfs = vswitchd.Resources.Limits[corev1.ResourceName("openshift.io/physnet4")]
+				g.Expect(vfs.String()).To(Equal("1"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the pre-existing network attachment definitions it does not manage", func() {
+			extNADName := types.NamespacedName{Namespace: namespace, Name: "external"}
+			nadInstance := CreateNAD(extNADName)
+			DeferCleanup(th.DeleteInstance, nadInstance)
+
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.NicMappings["external"] = "enp6s0"
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNController(ovnControllerName).Status.UnmanagedNetworkAttachments).To(
+					Equal([]string{"external"}))
+			}, timeout, interval).Should(Succeed())
+			Expect(GetNAD(extNADName).Spec.Config).To(BeEmpty())
+		})
+
+		It("rejects an sriov mapping without resource name", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.NicMappingsConfig["physnet4"] = ovnv1.NicMappingConfig{
+					Type: ovnv1.NicMappingTypeSRIOV,
+				}
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.nicMappingsConfig[physnet4].resourceName: Required value"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController is created with DPDK", func() {
+		var ovnControllerName types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.Resources = corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			}
+			spec.Bridges = []ovnv1.OVSBridge{
+				{
+					Name:             "br-dpdk",
+					PhysicalNetworks: []string{"datacentre"},
+					Uplink: &ovnv1.OVSBridgeUplink{
+						Interfaces: []string{"dpdk0", "dpdk1"},
+					},
+				},
+			}
+			spec.DPDK = &ovnv1.OVSDPDKSpec{
+				PMDCPUMask:   "0xc",
+				SocketMemory: "1024,1024",
+				Hugepages:    resource.MustParse("2Gi"),
+				Devices: map[string]string{
+					"dpdk0": "0000:03:00.0",
+					"dpdk1": "0000:03:00.1",
+				},
+			}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+		})
+
+		It("applies the DPDK defaults", func() {
+			dpdk := GetOVNController(ovnControllerName).Spec.DPDK
+			Expect(dpdk.DPDKInit).To(Equal("true"))
+			Expect(dpdk.HugepageSize).To(Equal("1Gi"))
+		})
+
+		It("gives ovs-vswitchd hugepages and VFIO devices", func() {
+			daemonSetNameOVS := types.NamespacedName{
+				Namespace: namespace,
+				Name:      "ovn-controller-ovs",
+			}
+			Eventually(func(g Gomega) {
+				podSpec := GetDaemonSet(daemonSetNameOVS).Spec.Template.Spec
+				vswitchd := podSpec.Containers[1]
+				g.Expect(vswitchd.Name).To(Equal("ovs-vswitchd"))
+				hugepages := vswitchd.Resources.Requests[corev1.ResourceName("hugepages-1Gi")]
+				g.Expect(hugepages.String()).To(Equal("2Gi"))
+				hugepages = vswitchd.Resources.Limits[corev1.ResourceName("hugepages-1Gi")]
+				g.Expect(hugepages.String()).To(Equal("2Gi"))
+				g.Expect(vswitchd.SecurityContext.Capabilities.Add).To(ContainElement(corev1.Capability("IPC_LOCK")))
+				g.Expect(GetEnvVarValue(vswitchd.Env, "OVSPMDCPUMask", "")).To(Equal("0xc"))
+				g.Expect(GetEnvVarValue(vswitchd.Env, "OVSDPDKSocketMem", "")).To(Equal("1024,1024"))
+
+				mounts := map[string]string{}
+				for _, mount := range vswitchd.VolumeMounts {
+					mounts[mount.Name] = mount.MountPath
+				}
+				g.Expect(mounts).To(HaveKeyWithValue("hugepages", "/dev/hugepages"))
+				g.Expect(mounts).To(HaveKeyWithValue("dev-vfio", "/dev/vfio"))
+				g.Expect(GetHostPath(podSpec.Volumes, "dev-vfio")).To(Equal("/dev/vfio"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("passes the DPDK settings to the config agent", func() {
+			Eventually(func(g Gomega) {
+				config := GetAgentConfig(ovnControllerName)
+				g.Expect(config).To(HaveKeyWithValue("OVSDatapathType", "netdev"))
+				g.Expect(config).To(HaveKeyWithValue("OVSDPDKInit", "true"))
+				g.Expect(config).To(HaveKeyWithValue("OVSDPDKDevices",
+					"dpdk0=0000:03:00.0 dpdk1=0000:03:00.1"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("does not create network attachments for the DPDK devices", func() {
+			Consistently(func(g Gomega) {
+				for _, iface := range []string{"dpdk0", "dpdk1"} {
+					nad := &networkv1.NetworkAttachmentDefinition{}
+					err := k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: iface}, nad)
+					g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+				}
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects a DPDK device which is not a bridge uplink", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.DPDK.Devices["dpdk2"] = "0000:04:00.0"
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.dpdk.devices[dpdk2]: Invalid value"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController is created with hardware offload", func() {
+		var ovnControllerName types.NamespacedName
+		var daemonSetNameOVS types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.HardwareOffload = &ovnv1.OVSHardwareOffloadSpec{
+				TCPolicy:          "skip_sw",
+				PhysicalFunctions: []string{"ens1f0", "ens1f1"},
+			}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+			daemonSetNameOVS = types.NamespacedName{
+				Namespace: namespace,
+				Name:      "ovn-controller-ovs",
+			}
+		})
+
+		It("switches the PFs to switchdev mode before OVS starts", func() {
+			Eventually(func(g Gomega) {
+				podSpec := GetDaemonSet(daemonSetNameOVS).Spec.Template.Spec
+				g.Expect(podSpec.InitContainers).To(HaveLen(2))
+				switchdev := podSpec.InitContainers[0]
+				g.Expect(switchdev.Name).To(Equal("ovs-switchdev-init"))
+				g.Expect(GetEnvVarValue(switchdev.Env, "OVSSwitchdevPFs", "")).To(Equal("ens1f0 ens1f1"))
+				g.Expect(GetHostPath(podSpec.Volumes, "host-proc")).To(Equal("/proc"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("enables hardware offload in ovs-vswitchd", func() {
+			Eventually(func(g Gomega) {
+				vswitchd := GetDaemonSet(daemonSetNameOVS).Spec.Template.Spec.Containers[1]
+				g.Expect(vswitchd.Name).To(Equal("ovs-vswitchd"))
+				g.Expect(GetEnvVarValue(vswitchd.Env, "OVSHWOffload", "")).To(Equal("true"))
+				g.Expect(GetEnvVarValue(vswitchd.Env, "OVSTCPolicy", "")).To(Equal("skip_sw"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the offload state of each node", func() {
+			SimulateDaemonsetNumberReadyWithPods(
+				daemonSetNameOVS,
+				map[string][]string{},
+			)
+			pod := GetPod(daemonSetNameOVS)
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations["ovn.openstack.org/hw-offload"] = "true"
+			pod.Annotations["ovn.openstack.org/offloaded-flows"] = "42"
+			UpdatePod(pod)
+
+			Eventually(func(g Gomega) {
+				nodes := GetOVNController(ovnControllerName).Status.Nodes
+				g.Expect(nodes).To(HaveKey(pod.Spec.NodeName))
+				g.Expect(nodes[pod.Spec.NodeName].HardwareOffload).To(Equal("true"))
+				g.Expect(nodes[pod.Spec.NodeName].OffloadedFlows).To(HaveValue(Equal(int64(42))))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects hardware offload together with DPDK", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.DPDK = &ovnv1.OVSDPDKSpec{
+					Hugepages: resource.MustParse("1Gi"),
+				}
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.hardwareOffload: Forbidden"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController is created with tunable external-ids", func() {
+		var ovnControllerName types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.ExternalIDS.OvnRemoteProbeInterval = ptr.To[int32](60000)
+			spec.ExternalIDS.OvnMonitorAll = ptr.To(true)
+			spec.ExternalIDS.OvnEncapTos = "inherit"
+			spec.ExternalIDS.ExtraExternalIDs = map[string]string{
+				"ovn-limit-lflow-cache": "500000",
+			}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+		})
+
+		It("passes the external-ids to the config agent", func() {
+			Eventually(func(g Gomega) {
+				config := GetAgentConfig(ovnControllerName)
+				g.Expect(config).To(HaveKeyWithValue("OVNExternalIDs",
+					"ovn-encap-tos=inherit ovn-limit-lflow-cache=500000 ovn-monitor-all=true ovn-remote-probe-interval=60000"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("passes extra external-id values as they are", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.ExternalIDS.ExtraExternalIDs["ovn-cms-tag"] = "{{.Name}}"
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				config := GetAgentConfig(ovnControllerName)
+				g.Expect(config["OVNExternalIDs"]).To(ContainSubstring("ovn-cms-tag={{.Name}}"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects an extra external-id managed by the operator", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.ExternalIDS.ExtraExternalIDs["ovn-remote"] = "tcp:127.0.0.1:6642"
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.external-ids.extraExternalIDs[ovn-remote]: Forbidden"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController is created with multiple encap types and encap network attachments", func() {
+		var ovnControllerName types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			for _, name := range []string{"internalapi", "tenant"} {
+				nad := th.CreateNetworkAttachmentDefinition(types.NamespacedName{Namespace: namespace, Name: name})
+				DeferCleanup(th.DeleteInstance, nad)
+			}
+			spec := GetDefaultOVNControllerSpec()
+			spec.ExternalIDS.OvnEncapType = "geneve,vxlan"
+			spec.NetworkAttachment = "internalapi"
+			spec.EncapNetworkAttachments = []string{"tenant"}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+		})
+
+		It("attaches the encap networks to the OVS pods", func() {
+			daemonSetNameOVS := types.NamespacedName{
+				Namespace: namespace,
+				Name:      "ovn-controller-ovs",
+			}
+			expectedAnnotation, err := json.Marshal(
+				[]networkv1.NetworkSelectionElement{
+					{
+						Name:             "internalapi",
+						Namespace:        namespace,
+						InterfaceRequest: "internalapi",
+					},
+					{
+						Name:             "tenant",
+						Namespace:        namespace,
+						InterfaceRequest: "tenant",
+					},
+				})
+			Expect(err).ShouldNot(HaveOccurred())
+			Eventually(func(g Gomega) {
+				g.Expect(GetDaemonSet(daemonSetNameOVS).Spec.Template.ObjectMeta.Annotations).To(
+					HaveKeyWithValue("k8s.v1.cni.cncf.io/networks", string(expectedAnnotation)))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("renders the encap NICs into the scripts", func() {
+			scriptsCM := types.NamespacedName{
+				Namespace: namespace,
+				Name:      ovnControllerName.Name + "-scripts",
+			}
+			Eventually(func(g Gomega) {
+				g.Expect(th.GetConfigMap(scriptsCM).Data["start-vswitchd.sh"]).To(
+					ContainSubstring("for nic in internalapi tenant; do"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects a duplicated encap type", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.ExternalIDS.OvnEncapType = "vxlan,vxlan"
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.external-ids.ovn-encap-type: Duplicate value"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects the default network attachment as encap network attachment", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.EncapNetworkAttachments = []string{"internalapi"}
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.encapNetworkAttachments[0]: Invalid value"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController config changes are rolled out", func() {
+		var ovnControllerName types.NamespacedName
+		var agentCM types.NamespacedName
+		var generation string
+		nodes := []string{"node-0", "node-1", "node-2"}
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.ConfigRollout.MaxParallel = ptr.To(intstr.FromInt32(1))
+			spec.ConfigRollout.MaxFailed = ptr.To(intstr.FromInt32(0))
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+			agentCM = types.NamespacedName{Namespace: namespace, Name: ovnControllerName.Name + "-agent-config"}
+			for _, node := range nodes {
+				pod := CreateOVNControllerPod(ovnControllerName, node)
+				DeferCleanup(th.DeleteInstance, pod)
+			}
+
+			// fresh nodes get the current generation right away
+			Eventually(func(g Gomega) {
+				generation = GetOVNController(ovnControllerName).Status.Hash[ovnv1.OVNConfigHash]
+				g.Expect(generation).ToNot(BeEmpty())
+				data := th.GetConfigMap(agentCM).Data
+				for _, node := range nodes {
+					g.Expect(data).To(HaveKeyWithValue("node."+node, generation))
+				}
+			}, timeout, interval).Should(Succeed())
+			for _, node := range nodes {
+				AnnotateOVNControllerPod(namespace, node, "ovn.openstack.org/config-generation", generation)
+			}
+			th.ExpectCondition(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerConfigRolloutReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		changeConfig := func() string {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.ExternalIDS.OvnMonitorAll = ptr.To(true)
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			var newGeneration string
+			Eventually(func(g Gomega) {
+				newGeneration = GetOVNController(ovnControllerName).Status.Hash[ovnv1.OVNConfigHash]
+				g.Expect(newGeneration).ToNot(Equal(generation))
+			}, timeout, interval).Should(Succeed())
+			return newGeneration
+		}
+
+		It("rolls a change out one node at a time", func() {
+			newGeneration := changeConfig()
+
+			Eventually(func(g Gomega) {
+				data := th.GetConfigMap(agentCM).Data
+				g.Expect(data).To(HaveKeyWithValue("node.node-0", newGeneration))
+				g.Expect(data).To(HaveKeyWithValue("node.node-1", generation))
+				g.Expect(data).To(HaveKeyWithValue("node.node-2", generation))
+				g.Expect(data).To(HaveKey(generation + ".env"))
+				g.Expect(data).To(HaveKey(newGeneration + ".env"))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerConfigRolloutReadyCondition,
+				corev1.ConditionFalse,
+			)
+
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/config-generation", newGeneration)
+			Eventually(func(g Gomega) {
+				data := th.GetConfigMap(agentCM).Data
+				g.Expect(data).To(HaveKeyWithValue("node.node-1", newGeneration))
+				g.Expect(data).To(HaveKeyWithValue("node.node-2", generation))
+				rollout := GetOVNController(ovnControllerName).Status.ConfigRollout
+				g.Expect(rollout.UpdatedNodes).To(Equal(int32(1)))
+				g.Expect(rollout.TotalNodes).To(Equal(int32(len(nodes))))
+			}, timeout, interval).Should(Succeed())
+
+			AnnotateOVNControllerPod(namespace, "node-1", "ovn.openstack.org/config-generation", newGeneration)
+			Eventually(func(g Gomega) {
+				g.Expect(th.GetConfigMap(agentCM).Data).To(HaveKeyWithValue("node.node-2", newGeneration))
+			}, timeout, interval).Should(Succeed())
+
+			AnnotateOVNControllerPod(namespace, "node-2", "ovn.openstack.org/config-generation", newGeneration)
+			th.ExpectCondition(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerConfigRolloutReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(th.GetConfigMap(agentCM).Data).ToNot(HaveKey(generation + ".env"))
+		})
+
+		It("halts the rollout when more nodes fail than allowed", func() {
+			newGeneration := changeConfig()
+
+			Eventually(func(g Gomega) {
+				g.Expect(th.GetConfigMap(agentCM).Data).To(HaveKeyWithValue("node.node-0", newGeneration))
+			}, timeout, interval).Should(Succeed())
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/config-error", newGeneration+": ovs-vsctl: unix:/var/run/openvswitch/db.sock: database connection failed")
+
+			Eventually(func(g Gomega) {
+				rollout := GetOVNController(ovnControllerName).Status.ConfigRollout
+				g.Expect(rollout.Halted).To(BeTrue())
+				g.Expect(rollout.FailedNodes).To(HaveKeyWithValue("node-0",
+					"ovs-vsctl: unix:/var/run/openvswitch/db.sock: database connection failed"))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectConditionWithDetails(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerConfigRolloutReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf(ovnv1.OVNControllerConfigRolloutReadyHaltedMessage, 1, "node-0"),
+			)
+			Consistently(func(g Gomega) {
+				data := th.GetConfigMap(agentCM).Data
+				g.Expect(data).To(HaveKeyWithValue("node.node-1", generation))
+				g.Expect(data).To(HaveKeyWithValue("node.node-2", generation))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVS pods report the restore of the flows", func() {
+		var ovnControllerName types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			instance := CreateOVNController(namespace, GetDefaultOVNControllerSpec())
+			DeferCleanup(th.DeleteInstance, instance)
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+
+			Eventually(func(g Gomega) {
+				vswitchd := GetDaemonSet(types.NamespacedName{Namespace: namespace, Name: "ovn-controller-ovs"}).Spec.Template.Spec.Containers[1]
+				g.Expect(vswitchd.Name).To(Equal("ovs-vswitchd"))
+				g.Expect(vswitchd.Env).To(ContainElement(HaveField("Name", "OVSPodName")))
+			}, timeout, interval).Should(Succeed())
+			DeferCleanup(th.DeleteInstance, CreateOVSPod(ovnControllerName, "node-0"))
+		})
+
+		getEventReasons := func(g Gomega) []string {
+			events := &corev1.EventList{}
+			g.Expect(k8sClient.List(ctx, events, client.InNamespace(namespace))).Should(Succeed())
+			reasons := []string{}
+			for _, event := range events.Items {
+				if event.InvolvedObject.Name == ovnControllerName.Name {
+					reasons = append(reasons, event.Reason)
+				}
+			}
+			return reasons
+		}
+
+		It("reports the flow restore of each node with Events", func() {
+			AnnotateOVSPod(namespace, "node-0", "ovn.openstack.org/flow-restore",
+				"result=Succeeded saved=120 restored=120 time=2026-10-19T10:00:00Z")
+			Eventually(func(g Gomega) {
+				restore := GetOVNController(ovnControllerName).Status.Nodes["node-0"].FlowRestore
+				g.Expect(restore).ToNot(BeNil())
+				g.Expect(restore.Result).To(Equal(ovnv1.FlowRestoreSucceeded))
+				g.Expect(restore.SavedFlows).To(Equal(int64(120)))
+				g.Expect(restore.RestoredFlows).To(Equal(int64(120)))
+				g.Expect(restore.IsHitless()).To(BeTrue())
+				g.Expect(getEventReasons(g)).To(ContainElement("FlowRestoreSucceeded"))
+			}, timeout, interval).Should(Succeed())
+
+			AnnotateOVSPod(namespace, "node-0", "ovn.openstack.org/flow-restore",
+				"result=Failed saved=120 restored=0 time=2026-10-19T11:00:00Z")
+			Eventually(func(g Gomega) {
+				restore := GetOVNController(ovnControllerName).Status.Nodes["node-0"].FlowRestore
+				g.Expect(restore.Result).To(Equal(ovnv1.FlowRestoreFailed))
+				g.Expect(restore.IsHitless()).To(BeFalse())
+				g.Expect(getEventReasons(g)).To(ContainElement("FlowRestoreFailed"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("ignores a malformed report", func() {
+			AnnotateOVSPod(namespace, "node-0", "ovn.openstack.org/hw-offload", "false")
+			AnnotateOVSPod(namespace, "node-0", "ovn.openstack.org/flow-restore", "result=Succeeded saved=many")
+			Eventually(func(g Gomega) {
+				nodes := GetOVNController(ovnControllerName).Status.Nodes
+				g.Expect(nodes).To(HaveKey("node-0"))
+				g.Expect(nodes["node-0"].HardwareOffload).To(Equal("false"))
+				g.Expect(nodes["node-0"].FlowRestore).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController reports the health of ovn-controller", func() {
+		var ovnControllerName types.NamespacedName
+		ovnDaemonSetName := types.NamespacedName{Name: "ovn-controller"}
+
+		BeforeEach(func() {
+			ovnDaemonSetName.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			instance := CreateOVNController(namespace, GetDefaultOVNControllerSpec())
+			DeferCleanup(th.DeleteInstance, instance)
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+		})
+
+		It("probes ovn-controller", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetDaemonSet(ovnDaemonSetName).Spec.Template.Spec.Containers[0]
+				g.Expect(ovnController.Name).To(Equal("ovn-controller"))
+				g.Expect(ovnController.LivenessProbe.Exec.Command).To(Equal([]string{
+					"/usr/local/bin/container-scripts/ovn-controller-probe.sh", "liveness"}))
+				g.Expect(ovnController.ReadinessProbe.Exec.Command).To(Equal([]string{
+					"/usr/local/bin/container-scripts/ovn-controller-probe.sh", "readiness"}))
+				g.Expect(ovnController.Env).To(ContainElement(HaveField("Name", "OVNPodName")))
+				g.Expect(ovnController.Env).To(ContainElement(corev1.EnvVar{Name: "ProbeTimeout", Value: "5"}))
+				// the readiness probe outlasts its commands run one after the other
+				g.Expect(ovnController.ReadinessProbe.TimeoutSeconds).To(BeNumerically(">", 7*5))
+				// the main loop is only found stuck after longer than a full recompute
+				g.Expect(ovnController.Env).To(ContainElement(corev1.EnvVar{Name: "LivenessTimeout", Value: "10"}))
+				g.Expect(ovnController.LivenessProbe.TimeoutSeconds).To(BeNumerically(">", 10))
+				g.Expect(ovnController.LivenessProbe.PeriodSeconds * ovnController.LivenessProbe.FailureThreshold).
+					To(BeNumerically(">=", 300))
+				g.Expect(ovnController.StartupProbe.Exec.Command).To(Equal(ovnController.LivenessProbe.Exec.Command))
+				g.Expect(ovnController.StartupProbe.PeriodSeconds * ovnController.StartupProbe.FailureThreshold).
+					To(BeNumerically(">=", 600))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the nodes where ovn-controller is degraded", func() {
+			DeferCleanup(th.DeleteInstance, CreateOVSPod(ovnControllerName, "node-0"))
+			DeferCleanup(th.DeleteInstance, CreateReadyDaemonSetPod(ovnDaemonSetName, "node-0"), client.GracePeriodSeconds(0))
+			AnnotatePod(types.NamespacedName{Namespace: namespace, Name: "ovn-controller-node-0"}, "ovn.openstack.org/health", "ok")
+			Eventually(func(g Gomega) {
+				nodes := GetOVNController(ovnControllerName).Status.Nodes
+				g.Expect(nodes).To(HaveKey("node-0"))
+				g.Expect(nodes["node-0"].Degraded).To(BeFalse())
+			}, timeout, interval).Should(Succeed())
+
+			AnnotatePod(types.NamespacedName{Namespace: namespace, Name: "ovn-controller-node-0"},
+				"ovn.openstack.org/health", "not connected to the SB DB: not connected")
+			Eventually(func(g Gomega) {
+				node := GetOVNController(ovnControllerName).Status.Nodes["node-0"]
+				g.Expect(node.Degraded).To(BeTrue())
+				g.Expect(node.DegradedReason).To(Equal("not connected to the SB DB: not connected"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the chassis image once every node runs it and reports its version", func() {
+			markRolledOut := func(image string) {
+				for _, name := range []string{"ovn-controller", "ovn-controller-ovs"} {
+					Eventually(func(g Gomega) {
+						ds := GetDaemonSet(types.NamespacedName{Namespace: namespace, Name: name})
+						if name == "ovn-controller" {
+							g.Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal(image))
+						}
+						ds.Status.ObservedGeneration = ds.Generation
+						g.Expect(k8sClient.Status().Update(ctx, ds)).To(Succeed())
+					}, timeout, interval).Should(Succeed())
+				}
+			}
+			oldImage := GetOVNController(ovnControllerName).Spec.OvnContainerImage
+			newImage := "quay.io/podified-antelope-centos9/openstack-ovn-controller:new"
+
+			markRolledOut(oldImage)
+			pod := CreateOVNControllerPod(ovnControllerName, "node-0")
+			DeferCleanup(th.DeleteInstance, pod, client.GracePeriodSeconds(0))
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/ovn-version", "24.03.2")
+			Eventually(func(g Gomega) {
+				status := GetOVNController(ovnControllerName).Status
+				g.Expect(status.Nodes["node-0"].OVNImage).To(Equal(oldImage))
+				g.Expect(status.ChassisOVNVersion).To(Equal("24.03.2"))
+				g.Expect(status.ChassisOvnImage).To(Equal(oldImage))
+			}, timeout, interval).Should(Succeed())
+
+			// the version reported by the pod still running the old image does not count
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.OvnContainerImage = newImage
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+			markRolledOut(newImage)
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNController(ovnControllerName).Status.ChassisOVNVersion).To(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(GetOVNController(ovnControllerName).Status.ChassisOvnImage).To(Equal(oldImage))
+			}, timeout/2, interval).Should(Succeed())
+
+			th.DeleteInstance(pod, client.GracePeriodSeconds(0))
+			DeferCleanup(th.DeleteInstance, CreateOVNControllerPod(ovnControllerName, "node-0"), client.GracePeriodSeconds(0))
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/ovn-version", "24.09.0")
+			Eventually(func(g Gomega) {
+				status := GetOVNController(ovnControllerName).Status
+				g.Expect(status.ChassisOVNVersion).To(Equal("24.09.0"))
+				g.Expect(status.ChassisOvnImage).To(Equal(newImage))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController is created with graceful restart", func() {
+		var ovnControllerName types.NamespacedName
+		ovnDaemonSetName := types.NamespacedName{Name: "ovn-controller"}
+		ovsDaemonSetName := types.NamespacedName{Name: "ovn-controller-ovs"}
+
+		BeforeEach(func() {
+			ovnDaemonSetName.Namespace = namespace
+			ovsDaemonSetName.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.GracefulRestart = &ovnv1.OVNControllerGracefulRestart{}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+		})
+
+		It("exits ovn-controller without clearing the flows", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetDaemonSet(ovnDaemonSetName).Spec.Template.Spec.Containers[0]
+				g.Expect(ovnController.Lifecycle.PreStop.Exec.Command).To(Equal([]string{
+					"/usr/local/bin/container-scripts/stop-ovn-controller.sh"}))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("lets the config agent measure the recompute time", func() {
+			Eventually(func(g Gomega) {
+				podSpec := GetDaemonSet(ovnDaemonSetName).Spec.Template.Spec
+				g.Expect(GetHostPath(podSpec.Volumes, "var-run-ovn")).To(HaveSuffix("/var/run/ovn"))
+				agent := podSpec.Containers[len(podSpec.Containers)-1]
+				g.Expect(agent.Name).To(Equal("ovn-config-agent"))
+				g.Expect(agent.VolumeMounts).To(ContainElement(HaveField("Name", "var-run-ovn")))
+
+				config := GetAgentConfig(ovnControllerName)
+				g.Expect(config).To(HaveKeyWithValue("OVNGracefulRestart", "true"))
+				g.Expect(config).To(HaveKeyWithValue("OVNWaitBeforeClearMin", "1000"))
+				g.Expect(config).To(HaveKeyWithValue("OVNWaitBeforeClearMax", "60000"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("leaves the OVS pods unchanged", func() {
+			Eventually(func(g Gomega) {
+				podSpec := GetDaemonSet(ovsDaemonSetName).Spec.Template.Spec
+				g.Expect(podSpec.Volumes).NotTo(ContainElement(HaveField("Name", "var-run-ovn")))
+				for _, container := range podSpec.Containers {
+					g.Expect(container.VolumeMounts).NotTo(ContainElement(HaveField("Name", "var-run-ovn")))
+				}
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the recompute time and the restart downtime of each node", func() {
+			DeferCleanup(th.DeleteInstance, CreateOVSPod(ovnControllerName, "node-0"))
+			DeferCleanup(th.DeleteInstance, CreateReadyDaemonSetPod(ovnDaemonSetName, "node-0"), client.GracePeriodSeconds(0))
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/recompute-time", "1500")
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/wait-before-clear", "3000")
+			AnnotatePod(types.NamespacedName{Namespace: namespace, Name: "ovn-controller-node-0"},
+				"ovn.openstack.org/restart-downtime", "250")
+
+			Eventually(func(g Gomega) {
+				node := GetOVNController(ovnControllerName).Status.Nodes["node-0"]
+				g.Expect(node.RecomputeTime).To(Equal(&metav1.Duration{Duration: 1500 * time.Millisecond}))
+				g.Expect(node.WaitBeforeClear).To(Equal(&metav1.Duration{Duration: 3 * time.Second}))
+				g.Expect(node.RestartDowntime).To(Equal(&metav1.Duration{Duration: 250 * time.Millisecond}))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects ovn-ofctrl-wait-before-clear set through external-ids", func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.ExternalIDS.OvnOfctrlWaitBeforeClear = ptr.To[int32](8000)
+				err := k8sClient.Update(ctx, ovnController)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.external-ids.ovn-ofctrl-wait-before-clear: Forbidden"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNController pod templates are rolled out with canary nodes", func() {
+		var ovnControllerName types.NamespacedName
+		var ovnDaemonSetName types.NamespacedName
+		var ovsDaemonSetName types.NamespacedName
+		nodes := []string{"canary-node", "node-a", "node-b"}
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNControllerSpec()
+			spec.ImageRollout = &ovnv1.OVNControllerImageRollout{
+				CanaryNodeSelector:      map[string]string{"ovn.openstack.org/canary": "true"},
+				WaveSize:                ptr.To(intstr.FromInt32(1)),
+				MinHealthySeconds:       ptr.To[int32](0),
+				ProgressDeadlineSeconds: 1,
+			}
+			instance := CreateOVNController(namespace, spec)
+			DeferCleanup(th.DeleteInstance, instance)
+
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+			ovnDaemonSetName = types.NamespacedName{Namespace: namespace, Name: "ovn-controller"}
+			ovsDaemonSetName = types.NamespacedName{Namespace: namespace, Name: "ovn-controller-ovs"}
+
+			for _, node := range nodes {
+				labels := map[string]string{}
+				if node == "canary-node" {
+					labels["ovn.openstack.org/canary"] = "true"
+				}
+				DeferCleanup(th.DeleteInstance, CreateNode(node, labels))
+			}
+			Eventually(func(g Gomega) {
+				for _, name := range []types.NamespacedName{ovnDaemonSetName, ovsDaemonSetName} {
+					ds := GetDaemonSet(name)
+					g.Expect(ds.Spec.UpdateStrategy.Type).To(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+					g.Expect(ds.Spec.Template.Labels).To(HaveKey("ovn.openstack.org/template-hash"))
+				}
+			}, timeout, interval).Should(Succeed())
+			for _, node := range nodes {
+				for _, name := range []types.NamespacedName{ovnDaemonSetName, ovsDaemonSetName} {
+					DeferCleanup(th.DeleteInstance, CreateReadyDaemonSetPod(name, node), client.GracePeriodSeconds(0))
+				}
+			}
+
+			Eventually(func(g Gomega) {
+				rollout := GetOVNController(ovnControllerName).Status.ImageRollout
+				g.Expect(rollout).ToNot(BeNil())
+				g.Expect(rollout.Phase).To(Equal(ovnv1.ImageRolloutPhaseComplete))
+				g.Expect(rollout.UpdatedNodes).To(Equal(int32(3)))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerImageRolloutReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		updateImage := func() {
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.OvsContainerImage = "quay.io/podified-antelope-centos9/openstack-ovn-base:canary"
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+		}
+
+		It("updates the canary nodes first and then the other nodes in waves", func() {
+			updateImage()
+
+			Eventually(func(g Gomega) {
+				rollout := GetOVNController(ovnControllerName).Status.ImageRollout
+				g.Expect(rollout.Phase).To(Equal(ovnv1.ImageRolloutPhaseCanary))
+				g.Expect(rollout.WaveNodes).To(Equal([]string{"canary-node"}))
+				g.Expect(IsPodDeleted(ovsDaemonSetName, "canary-node")).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+			// the ovn-controller template did not change
+			Expect(IsPodDeleted(ovnDaemonSetName, "canary-node")).To(BeFalse())
+			Expect(IsPodDeleted(ovsDaemonSetName, "node-a")).To(BeFalse())
+			Expect(IsPodDeleted(ovsDaemonSetName, "node-b")).To(BeFalse())
+
+			ReplaceDaemonSetPod(ovsDaemonSetName, "canary-node")
+			Eventually(func(g Gomega) {
+				rollout := GetOVNController(ovnControllerName).Status.ImageRollout
+				g.Expect(rollout.Phase).To(Equal(ovnv1.ImageRolloutPhaseWaves))
+				g.Expect(rollout.WaveNodes).To(Equal([]string{"node-a"}))
+				g.Expect(rollout.UpdatedNodes).To(Equal(int32(1)))
+				g.Expect(IsPodDeleted(ovsDaemonSetName, "node-a")).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+			Expect(IsPodDeleted(ovsDaemonSetName, "node-b")).To(BeFalse())
+
+			ReplaceDaemonSetPod(ovsDaemonSetName, "node-a")
+			Eventually(func(g Gomega) {
+				g.Expect(IsPodDeleted(ovsDaemonSetName, "node-b")).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			ReplaceDaemonSetPod(ovsDaemonSetName, "node-b")
+			Eventually(func(g Gomega) {
+				rollout := GetOVNController(ovnControllerName).Status.ImageRollout
+				g.Expect(rollout.Phase).To(Equal(ovnv1.ImageRolloutPhaseComplete))
+				g.Expect(rollout.UpdatedNodes).To(Equal(int32(3)))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerImageRolloutReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		It("pauses when the canary nodes do not become healthy", func() {
+			updateImage()
+
+			Eventually(func(g Gomega) {
+				g.Expect(IsPodDeleted(ovsDaemonSetName, "canary-node")).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				rollout := GetOVNController(ovnControllerName).Status.ImageRollout
+				g.Expect(rollout.Paused).To(BeTrue())
+				g.Expect(rollout.UnhealthyNodes).To(HaveKeyWithValue("canary-node", "pods not replaced yet"))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectConditionWithDetails(
+				ovnControllerName,
+				ConditionGetterFunc(OVNControllerConditionGetter),
+				ovnv1.OVNControllerImageRolloutReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf(ovnv1.OVNControllerImageRolloutReadyPausedMessage, "canary-node (pods not replaced yet)"),
+			)
+			Consistently(func(g Gomega) {
+				g.Expect(IsPodDeleted(ovsDaemonSetName, "node-a")).To(BeFalse())
+				g.Expect(IsPodDeleted(ovsDaemonSetName, "node-b")).To(BeFalse())
+			}, time.Second*3, interval).Should(Succeed())
+		})
+	})
 })
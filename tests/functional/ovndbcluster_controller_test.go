@@ -19,6 +19,7 @@ package functional_test
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
 	. "github.com/onsi/gomega"    //revive:disable:dot-imports
@@ -29,9 +30,12 @@ import (
 	networkv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovndbcluster"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 )
 
 var _ = Describe("OVNDBCluster controller", func() {
@@ -837,4 +841,182 @@ var _ = Describe("OVNDBCluster controller", func() {
 		})
 
 	})
+
+	When("OVNDBCluster members have client connections", func() {
+		var dbName types.NamespacedName
+		pod := types.NamespacedName{Name: "ovsdbserver-nb-0"}
+
+		BeforeEach(func() {
+			pod.Namespace = namespace
+			podExecutor.SetOutput(pod, ovndbcluster.AppctlCommand(ovnv1.NBDBType, "memory/show"),
+				"atoms:4096 cells:8192 monitors:12 raft-connections:2 raft-log:10 sessions:10 txn-history:64\n")
+			podExecutor.SetOutput(pod, ovndbcluster.AppctlCommand(ovnv1.NBDBType, "ovsdb-server/list-remotes"),
+				"ptcp:6641:0.0.0.0\npunix:/tmp/ovnnb_db.sock\n")
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			dbName = dbs[0]
+			SimulatePodRunning(pod)
+			// the DB pods are not watched, the next check happens after an update
+			Eventually(func(g Gomega) {
+				cluster := GetOVNDBCluster(dbName)
+				cluster.Annotations = map[string]string{"test": "connections"}
+				g.Expect(k8sClient.Update(ctx, cluster)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the connections of each member", func() {
+			Eventually(func(g Gomega) {
+				status := GetOVNDBCluster(dbName).Status
+				g.Expect(status.Connections).To(Equal(map[string]ovnv1.OVNDBConnections{
+					"ovsdbserver-nb-0": {
+						Sessions:        10,
+						Monitors:        12,
+						RaftConnections: 2,
+						Remotes:         []string{"ptcp:6641:0.0.0.0", "punix:/tmp/ovnnb_db.sock"},
+					},
+				}))
+				g.Expect(status.ClientConnections).To(Equal(int64(10)))
+				g.Expect(status.ConnectionsCheckTime).NotTo(BeNil())
+			}, timeout, interval).Should(Succeed())
+			th.ExpectConditionWithDetails(
+				dbName,
+				ConditionGetterFunc(OVNDBClusterConditionGetter),
+				ovnv1.OVNDBClusterClientConnectionsStableCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf(ovnv1.OVNDBClusterClientConnectionsStableMessage, 10),
+			)
+		})
+
+		It("warns when the client connections dropped sharply without affecting Ready", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNDBCluster(dbName).Status.ConnectionsCheckTime).NotTo(BeNil())
+			}, timeout, interval).Should(Succeed())
+
+			// a previous check with 30 clients, long enough ago for the next one to run
+			Eventually(func(g Gomega) {
+				cluster := GetOVNDBCluster(dbName)
+				cluster.Status.ClientConnections = 30
+				cluster.Status.ConnectionsCheckTime = ptr.To(metav1.NewTime(time.Now().Add(-2 * time.Minute)))
+				g.Expect(k8sClient.Status().Update(ctx, cluster)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			th.ExpectConditionWithDetails(
+				dbName,
+				ConditionGetterFunc(OVNDBClusterConditionGetter),
+				ovnv1.OVNDBClusterClientConnectionsStableCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf(ovnv1.OVNDBClusterClientConnectionsDroppedMessage, 30, 10),
+			)
+			th.ExpectCondition(
+				dbName,
+				ConditionGetterFunc(OVNDBClusterConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		It("checks the connections again as soon as a client pod changes", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNDBCluster(dbName).Status.ConnectionsCheckTime).NotTo(BeNil())
+			}, timeout, interval).Should(Succeed())
+
+			// a recent check with 30 clients, the next one is not due
+			Eventually(func(g Gomega) {
+				cluster := GetOVNDBCluster(dbName)
+				cluster.Status.ClientConnections = 30
+				cluster.Status.ConnectionsCheckTime = ptr.To(metav1.Now())
+				g.Expect(k8sClient.Status().Update(ctx, cluster)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(GetOVNDBCluster(dbName).Status.ClientConnections).To(Equal(int64(30)))
+			}, time.Second, interval).Should(Succeed())
+
+			northdPod := CreateRunningPod(
+				types.NamespacedName{Namespace: namespace, Name: "ovn-northd-0"},
+				map[string]string{"service": "ovn-northd"}, "ovn-northd")
+			DeferCleanup(th.DeleteInstance, northdPod)
+			northdPod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+			Expect(k8sClient.Status().Update(ctx, northdPod)).Should(Succeed())
+
+			th.ExpectConditionWithDetails(
+				dbName,
+				ConditionGetterFunc(OVNDBClusterConditionGetter),
+				ovnv1.OVNDBClusterClientConnectionsStableCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf(ovnv1.OVNDBClusterClientConnectionsDroppedMessage, 30, 10),
+			)
+		})
+	})
+
+	When("OVNDBCluster has a member whose connections can not be queried", func() {
+		var dbName types.NamespacedName
+		pods := []types.NamespacedName{{Name: "ovsdbserver-nb-0"}, {Name: "ovsdbserver-nb-1"}}
+
+		BeforeEach(func() {
+			pods[0].Namespace = namespace
+			pods[1].Namespace = namespace
+			// only the first member answers
+			podExecutor.SetOutput(pods[0], ovndbcluster.AppctlCommand(ovnv1.NBDBType, "memory/show"),
+				"monitors:12 raft-connections:2 sessions:10\n")
+			podExecutor.SetOutput(pods[0], ovndbcluster.AppctlCommand(ovnv1.NBDBType, "ovsdb-server/list-remotes"),
+				"ptcp:6641:0.0.0.0\n")
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 2)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			dbName = dbs[0]
+			for _, pod := range pods {
+				SimulatePodRunning(pod)
+			}
+		})
+
+		// a previous check long enough ago for the next one to run
+		setPreviousCheck := func(connections map[string]ovnv1.OVNDBConnections, clients int64) {
+			Eventually(func(g Gomega) {
+				cluster := GetOVNDBCluster(dbName)
+				cluster.Status.Connections = connections
+				cluster.Status.ClientConnections = clients
+				cluster.Status.ConnectionsCheckTime = ptr.To(metav1.NewTime(time.Now().Add(-2 * time.Minute)))
+				g.Expect(k8sClient.Status().Update(ctx, cluster)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+		}
+
+		It("keeps the previous connections of the member instead of reporting a drop", func() {
+			setPreviousCheck(map[string]ovnv1.OVNDBConnections{
+				"ovsdbserver-nb-0": {Sessions: 10},
+				"ovsdbserver-nb-1": {Sessions: 20},
+			}, 30)
+
+			Eventually(func(g Gomega) {
+				status := GetOVNDBCluster(dbName).Status
+				g.Expect(status.Connections["ovsdbserver-nb-0"].Remotes).To(Equal([]string{"ptcp:6641:0.0.0.0"}))
+				g.Expect(status.Connections).To(HaveKeyWithValue("ovsdbserver-nb-1", ovnv1.OVNDBConnections{Sessions: 20}))
+				g.Expect(status.ClientConnections).To(Equal(int64(30)))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectConditionWithDetails(
+				dbName,
+				ConditionGetterFunc(OVNDBClusterConditionGetter),
+				ovnv1.OVNDBClusterClientConnectionsStableCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf(ovnv1.OVNDBClusterClientConnectionsStableMessage, 30),
+			)
+		})
+
+		It("does not compare the client connections without previous connections of the member", func() {
+			setPreviousCheck(map[string]ovnv1.OVNDBConnections{"ovsdbserver-nb-0": {Sessions: 10}}, 30)
+
+			Eventually(func(g Gomega) {
+				status := GetOVNDBCluster(dbName).Status
+				g.Expect(status.Connections["ovsdbserver-nb-0"].Remotes).To(Equal([]string{"ptcp:6641:0.0.0.0"}))
+				g.Expect(status.Connections).NotTo(HaveKey("ovsdbserver-nb-1"))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				cluster := GetOVNDBCluster(dbName)
+				g.Expect(cluster.Status.ClientConnections).To(Equal(int64(30)))
+				g.Expect(cluster.Status.Conditions.Has(ovnv1.OVNDBClusterClientConnectionsStableCondition)).To(BeFalse())
+			}, timeout/2, interval).Should(Succeed())
+		})
+	})
 })
@@ -0,0 +1,207 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package functional_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovndiagnostics"
+	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovnnorthd"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("OVNDiagnostics controller", func() {
+
+	When("OVNDiagnostics runs commands against ovn-northd", func() {
+		var pods []types.NamespacedName
+		northdLabels := map[string]string{"service": "ovn-northd"}
+
+		BeforeEach(func() {
+			pods = []types.NamespacedName{
+				{Namespace: namespace, Name: "ovn-northd-0"},
+				{Namespace: namespace, Name: "ovn-northd-1"},
+			}
+			for _, pod := range pods {
+				podExecutor.SetOutput(pod, ovnnorthd.AppctlCommand("status"), "Status: active\n")
+				DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, northdLabels, "ovn-northd"))
+			}
+		})
+
+		It("stores the output of each pod in a ConfigMap", func() {
+			diagnosticsName := CreateOVNDiagnostics(namespace, ovnv1.OVNDiagnosticsSpec{
+				Target:   ovnv1.ServiceNameOVNNorthd,
+				Commands: []string{"status", "memory/show"},
+			})
+			DeferCleanup(th.DeleteInstance, GetOVNDiagnostics(diagnosticsName))
+
+			th.ExpectCondition(
+				diagnosticsName,
+				ConditionGetterFunc(OVNDiagnosticsConditionGetter),
+				ovnv1.OVNDiagnosticsCompletedCondition,
+				corev1.ConditionTrue,
+			)
+			status := GetOVNDiagnostics(diagnosticsName).Status
+			Expect(status.OutputConfigMap).To(Equal(diagnosticsName.Name + "-output"))
+			Expect(status.CompletionTime).NotTo(BeNil())
+			// no output is set for memory/show, it fails
+			Expect(status.FailedCommands).To(ConsistOf("ovn-northd-0.memory-show", "ovn-northd-1.memory-show"))
+
+			cm := th.GetConfigMap(types.NamespacedName{Namespace: namespace, Name: status.OutputConfigMap})
+			Expect(cm.Data).To(HaveKeyWithValue("ovn-northd-0.status", "Status: active\n"))
+			Expect(cm.Data).To(HaveKeyWithValue("ovn-northd-1.status", "Status: active\n"))
+			Expect(cm.Data).To(HaveKey("ovn-northd-0.memory-show"))
+			Expect(cm.OwnerReferences).To(HaveLen(1))
+		})
+
+		It("runs the commands only in the selected pods", func() {
+			diagnosticsName := CreateOVNDiagnostics(namespace, ovnv1.OVNDiagnosticsSpec{
+				Target:   ovnv1.ServiceNameOVNNorthd,
+				Commands: []string{"status"},
+				Pods:     []string{"ovn-northd-1"},
+			})
+			DeferCleanup(th.DeleteInstance, GetOVNDiagnostics(diagnosticsName))
+
+			th.ExpectConditionWithDetails(
+				diagnosticsName,
+				ConditionGetterFunc(OVNDiagnosticsConditionGetter),
+				ovnv1.OVNDiagnosticsCompletedCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				"1 command(s) run, 0 failed",
+			)
+			cm := th.GetConfigMap(types.NamespacedName{Namespace: namespace, Name: diagnosticsName.Name + "-output"})
+			Expect(cm.Data).To(HaveLen(1))
+			Expect(cm.Data).To(HaveKey("ovn-northd-1.status"))
+		})
+
+		It("truncates the output of a command over the size limit", func() {
+			podExecutor.SetOutput(pods[0], ovnnorthd.AppctlCommand("memory/show"),
+				strings.Repeat("x", ovndiagnostics.MaxCommandOutput+1))
+			diagnosticsName := CreateOVNDiagnostics(namespace, ovnv1.OVNDiagnosticsSpec{
+				Target:   ovnv1.ServiceNameOVNNorthd,
+				Commands: []string{"status", "memory/show"},
+				Pods:     []string{"ovn-northd-0"},
+			})
+			DeferCleanup(th.DeleteInstance, GetOVNDiagnostics(diagnosticsName))
+
+			th.ExpectCondition(
+				diagnosticsName,
+				ConditionGetterFunc(OVNDiagnosticsConditionGetter),
+				ovnv1.OVNDiagnosticsCompletedCondition,
+				corev1.ConditionTrue,
+			)
+			status := GetOVNDiagnostics(diagnosticsName).Status
+			Expect(status.FailedCommands).To(BeEmpty())
+			Expect(status.TruncatedCommands).To(ConsistOf("ovn-northd-0.memory-show"))
+
+			cm := th.GetConfigMap(types.NamespacedName{Namespace: namespace, Name: status.OutputConfigMap})
+			Expect(cm.Data).To(HaveKeyWithValue("ovn-northd-0.status", "Status: active\n"))
+			Expect(cm.Data["ovn-northd-0.memory-show"]).To(HaveSuffix("[output truncated]\n"))
+			Expect(len(cm.Data["ovn-northd-0.memory-show"])).To(BeNumerically("<=", ovndiagnostics.MaxCommandOutput))
+		})
+
+		It("bounds the commands run over all pods", func() {
+			for i := 2; i < 6; i++ {
+				pod := types.NamespacedName{Namespace: namespace, Name: fmt.Sprintf("ovn-northd-%d", i)}
+				DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, northdLabels, "ovn-northd"))
+			}
+			commands := []string{"status", "inc-engine/show-stats", "nb-connection-status", "sb-connection-status",
+				"coverage/show", "memory/show", "vlog/list"}
+			diagnosticsName := CreateOVNDiagnostics(namespace, ovnv1.OVNDiagnosticsSpec{
+				Target:   ovnv1.ServiceNameOVNNorthd,
+				Commands: commands,
+			})
+			DeferCleanup(th.DeleteInstance, GetOVNDiagnostics(diagnosticsName))
+
+			th.ExpectCondition(
+				diagnosticsName,
+				ConditionGetterFunc(OVNDiagnosticsConditionGetter),
+				ovnv1.OVNDiagnosticsCompletedCondition,
+				corev1.ConditionTrue,
+			)
+			// 5 pods run 7 commands each, the 6th one would go over the limit
+			Expect(GetOVNDiagnostics(diagnosticsName).Status.SkippedPods).To(Equal(int32(1)))
+			cm := th.GetConfigMap(types.NamespacedName{Namespace: namespace, Name: diagnosticsName.Name + "-output"})
+			Expect(cm.Data).To(HaveLen(5 * len(commands)))
+			Expect(len(cm.Data)).To(BeNumerically("<=", ovnv1.OVNDiagnosticsMaxCommands))
+			Expect(cm.Data).NotTo(HaveKey("ovn-northd-5.status"))
+		})
+
+		It("refuses commands which are not read only", func() {
+			diagnosticsName := CreateOVNDiagnostics(namespace, ovnv1.OVNDiagnosticsSpec{
+				Target:   ovnv1.ServiceNameOVNNorthd,
+				Commands: []string{"status", "pause", "exit"},
+			})
+			DeferCleanup(th.DeleteInstance, GetOVNDiagnostics(diagnosticsName))
+
+			th.ExpectConditionWithDetails(
+				diagnosticsName,
+				ConditionGetterFunc(OVNDiagnosticsConditionGetter),
+				ovnv1.OVNDiagnosticsCompletedCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Commands not allowed for ovn-northd: exit,pause",
+			)
+			Expect(GetOVNDiagnostics(diagnosticsName).Status.OutputConfigMap).To(BeEmpty())
+		})
+	})
+
+	When("OVNDiagnostics targets a service without running pods", func() {
+		It("waits for them", func() {
+			diagnosticsName := CreateOVNDiagnostics(namespace, ovnv1.OVNDiagnosticsSpec{
+				Target:   ovnv1.ServiceNameNB,
+				Commands: []string{"cluster/status"},
+			})
+			DeferCleanup(th.DeleteInstance, GetOVNDiagnostics(diagnosticsName))
+
+			th.ExpectConditionWithDetails(
+				diagnosticsName,
+				ConditionGetterFunc(OVNDiagnosticsConditionGetter),
+				ovnv1.OVNDiagnosticsCompletedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"No running ovsdbserver-nb pod to run the commands in",
+			)
+
+			pod := types.NamespacedName{Namespace: namespace, Name: "ovsdbserver-nb-0"}
+			podExecutor.SetOutput(pod,
+				[]string{"ovn-appctl", "-t", "/tmp/ovnnb_db.ctl", "cluster/status", "OVN_Northbound"},
+				"Role: leader\n")
+			DeferCleanup(th.DeleteInstance,
+				CreateRunningPod(pod, map[string]string{"service": "ovsdbserver-nb"}, "ovsdbserver-nb"))
+
+			th.ExpectCondition(
+				diagnosticsName,
+				ConditionGetterFunc(OVNDiagnosticsConditionGetter),
+				ovnv1.OVNDiagnosticsCompletedCondition,
+				corev1.ConditionTrue,
+			)
+			cm := th.GetConfigMap(types.NamespacedName{Namespace: namespace, Name: diagnosticsName.Name + "-output"})
+			Expect(cm.Data).To(HaveKeyWithValue("ovsdbserver-nb-0.cluster-status", "Role: leader\n"))
+		})
+	})
+})
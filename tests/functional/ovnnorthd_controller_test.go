@@ -25,9 +25,16 @@ import (
 	//revive:disable-next-line:dot-imports
 	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
 
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
 	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/ovn-operator/pkg/ovnnorthd"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("OVNNorthd controller", func() {
@@ -85,6 +92,11 @@ var _ = Describe("OVNNorthd controller", func() {
 					"--ovnnb-db=tcp:ovsdbserver-nb-0." + namespace + ".svc.cluster.local:6641",
 					"--ovnsb-db=tcp:ovsdbserver-sb-0." + namespace + ".svc.cluster.local:6642",
 				}))
+				// without spec.livenessProbe a stuck ovn-northd is detected as quickly as before
+				livenessProbe := depl.Spec.Template.Spec.Containers[0].LivenessProbe
+				Expect(livenessProbe.TimeoutSeconds).To(Equal(int32(5)))
+				Expect(livenessProbe.PeriodSeconds).To(Equal(int32(3)))
+				Expect(livenessProbe.FailureThreshold).To(Equal(int32(3)))
 			})
 		})
 
@@ -318,4 +330,759 @@ var _ = Describe("OVNNorthd controller", func() {
 			}, timeout, interval).Should(Succeed())
 		})
 	})
+
+	When("OVNNorthd and OVNController images are updated together", func() {
+		var ovnNorthdName types.NamespacedName
+		var ovnControllerName types.NamespacedName
+		deplName := types.NamespacedName{Namespace: "", Name: "ovn-northd"}
+		newNorthdImage := "quay.io/podified-antelope-centos9/openstack-ovn-northd:new"
+
+		BeforeEach(func() {
+			deplName.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, GetDefaultOVNNorthdSpec())
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			instance := CreateOVNController(namespace, GetDefaultOVNControllerSpec())
+			DeferCleanup(th.DeleteInstance, instance)
+			ovnControllerName = types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.ContainerImage).To(Equal(ovnv1.OVNNorthdContainerImage))
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				ovnController := GetOVNController(ovnControllerName)
+				ovnController.Spec.OvnContainerImage = "quay.io/podified-antelope-centos9/openstack-ovn-controller:new"
+				g.Expect(k8sClient.Update(ctx, ovnController)).Should(Succeed())
+				ovnNorthd := GetOVNNorthd(ovnNorthdName)
+				ovnNorthd.Spec.ContainerImage = newNorthdImage
+				g.Expect(k8sClient.Update(ctx, ovnNorthd)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("holds the ovn-northd update until ovn-controller got updated on every chassis", func() {
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNUpgradeOrderReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNUpgradeOrderReadyVersionMessage, newNorthdImage),
+			)
+			versionPod := CompleteOVNVersionJob(GetOVNNorthd(ovnNorthdName), newNorthdImage, "24.09.0")
+			DeferCleanup(th.DeleteInstance, versionPod, client.GracePeriodSeconds(0))
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNUpgradeOrderReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNUpgradeOrderReadyHeldMessage, newNorthdImage, "24.09.0"),
+			)
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.UpgradePhase).To(Equal(ovnv1.UpgradePhaseChassis))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				depl := th.GetDeployment(deplName)
+				g.Expect(depl.Spec.Template.Spec.Containers[0].Image).To(Equal(ovnv1.OVNNorthdContainerImage))
+			}, timeout/2, interval).Should(Succeed())
+
+			// the DaemonSet controller reports both DaemonSets as rolled out
+			for _, name := range []string{"ovn-controller", "ovn-controller-ovs"} {
+				Eventually(func(g Gomega) {
+					ds := GetDaemonSet(types.NamespacedName{Namespace: namespace, Name: name})
+					ds.Status.ObservedGeneration = ds.Generation
+					g.Expect(k8sClient.Status().Update(ctx, ds)).To(Succeed())
+				}, timeout, interval).Should(Succeed())
+			}
+
+			Eventually(func(g Gomega) {
+				depl := th.GetDeployment(deplName)
+				g.Expect(depl.Spec.Template.Spec.Containers[0].Image).To(Equal(newNorthdImage))
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.ContainerImage).To(Equal(newNorthdImage))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNUpgradeOrderReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		It("holds the ovn-northd update while a chassis runs an older OVN version", func() {
+			versionPod := CompleteOVNVersionJob(GetOVNNorthd(ovnNorthdName), newNorthdImage, "24.09.0")
+			DeferCleanup(th.DeleteInstance, versionPod, client.GracePeriodSeconds(0))
+			for _, name := range []string{"ovn-controller", "ovn-controller-ovs"} {
+				Eventually(func(g Gomega) {
+					ds := GetDaemonSet(types.NamespacedName{Namespace: namespace, Name: name})
+					ds.Status.ObservedGeneration = ds.Generation
+					g.Expect(k8sClient.Status().Update(ctx, ds)).To(Succeed())
+				}, timeout, interval).Should(Succeed())
+			}
+			pod := CreateOVNControllerPod(ovnControllerName, "node-0")
+			DeferCleanup(th.DeleteInstance, pod, client.GracePeriodSeconds(0))
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/ovn-version", "24.03.2")
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNController(ovnControllerName).Status.ChassisOVNVersion).To(Equal("24.03.2"))
+			}, timeout, interval).Should(Succeed())
+
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNUpgradeOrderReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNUpgradeOrderReadyHeldMessage, newNorthdImage, "24.09.0"),
+			)
+			Consistently(func(g Gomega) {
+				depl := th.GetDeployment(deplName)
+				g.Expect(depl.Spec.Template.Spec.Containers[0].Image).To(Equal(ovnv1.OVNNorthdContainerImage))
+			}, timeout/2, interval).Should(Succeed())
+
+			AnnotateOVNControllerPod(namespace, "node-0", "ovn.openstack.org/ovn-version", "24.09.1")
+			Eventually(func(g Gomega) {
+				depl := th.GetDeployment(deplName)
+				g.Expect(depl.Spec.Template.Spec.Containers[0].Image).To(Equal(newNorthdImage))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNNorthd is created with probe timings", func() {
+		var ovnNorthdName types.NamespacedName
+		deplName := types.NamespacedName{Name: "ovn-northd"}
+
+		BeforeEach(func() {
+			deplName.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNNorthdSpec()
+			spec.LivenessProbe = &ovnv1.OVNNorthdProbe{
+				PeriodSeconds:    ptr.To[int32](20),
+				FailureThreshold: ptr.To[int32](9),
+			}
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+		})
+
+		It("probes ovn-northd through ovn-appctl with the given timings", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetDeployment(deplName).Spec.Template.Spec.Containers[0]
+				g.Expect(container.LivenessProbe.Exec.Command).To(Equal(ovnnorthd.AppctlCommand("status")))
+				g.Expect(container.LivenessProbe.PeriodSeconds).To(Equal(int32(20)))
+				g.Expect(container.LivenessProbe.FailureThreshold).To(Equal(int32(9)))
+				g.Expect(container.LivenessProbe.TimeoutSeconds).To(Equal(int32(5)))
+
+				g.Expect(container.ReadinessProbe.Exec.Command[2]).To(ContainSubstring("${db}-connection-status"))
+				g.Expect(container.ReadinessProbe.PeriodSeconds).To(Equal(int32(5)))
+				g.Expect(container.ReadinessProbe.FailureThreshold).To(Equal(int32(3)))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("leaves the probes without timings unset", func() {
+			spec := GetOVNNorthd(ovnNorthdName).Spec
+			Expect(spec.LivenessProbe.TimeoutSeconds).To(Equal(ptr.To[int32](5)))
+			Expect(spec.ReadinessProbe).To(BeNil())
+		})
+	})
+
+	When("OVNNorthd runs several replicas", func() {
+		var ovnNorthdName types.NamespacedName
+		northdLabels := map[string]string{"service": "ovn-northd"}
+		statusCmd := ovnnorthd.AppctlCommand("status")
+		var pods []types.NamespacedName
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNNorthdSpec()
+			spec.Replicas = ptr.To[int32](2)
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			pods = []types.NamespacedName{
+				{Namespace: namespace, Name: "ovn-northd-0"},
+				{Namespace: namespace, Name: "ovn-northd-1"},
+			}
+		})
+
+		createPods := func(states ...string) {
+			for i, pod := range pods {
+				podExecutor.SetOutput(pod, statusCmd, "Status: "+states[i]+"\n")
+				DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, northdLabels, "ovn-northd"))
+			}
+		}
+
+		It("reports the instance holding the SB lock", func() {
+			createPods("standby", "active")
+			Eventually(func(g Gomega) {
+				status := GetOVNNorthd(ovnNorthdName).Status
+				g.Expect(status.ActiveInstance).To(Equal("ovn-northd-1"))
+				g.Expect(status.Instances).To(Equal(map[string]string{
+					"ovn-northd-0": "standby",
+					"ovn-northd-1": "active",
+				}))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdActiveReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		It("does not query the instances again on every reconcile", func() {
+			createPods("standby", "active")
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.ActiveInstance).To(Equal("ovn-northd-1"))
+			}, timeout, interval).Should(Succeed())
+
+			podExecutor.SetOutput(pods[0], statusCmd, "Status: active\n")
+			podExecutor.SetOutput(pods[1], statusCmd, "Status: standby\n")
+			// Change something just to call reconcile loop
+			AnnotatePod(pods[0], "test", "reconcile")
+			Consistently(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.ActiveInstance).To(Equal("ovn-northd-1"))
+			}, timeout/2, interval).Should(Succeed())
+		})
+
+		It("reports when no instance holds the SB lock", func() {
+			createPods("standby", "standby")
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdActiveReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				ovnv1.OVNNorthdActiveReadyNoneMessage,
+			)
+		})
+
+		It("reports when several instances claim to be active", func() {
+			createPods("active", "active")
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdActiveReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				fmt.Sprintf(ovnv1.OVNNorthdActiveReadyMultipleMessage, "ovn-northd-0,ovn-northd-1"),
+			)
+			Expect(GetOVNNorthd(ovnNorthdName).Status.ActiveInstance).To(BeEmpty())
+		})
+	})
+
+	When("OVNNorthd is created with tuning options", func() {
+		var ovnNorthdName types.NamespacedName
+		jobName := types.NamespacedName{Name: ovnnorthd.NBGlobalJobName}
+		pod := types.NamespacedName{Name: "ovn-northd-0"}
+
+		BeforeEach(func() {
+			jobName.Namespace = namespace
+			pod.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNNorthdSpec()
+			spec.Tuning.BackoffIntervalMs = ptr.To[int32](200)
+			spec.Tuning.UseParallelBuild = ptr.To(true)
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+		})
+
+		It("applies the NB_Global options with a Job", func() {
+			Eventually(func(g Gomega) {
+				container := th.GetJob(jobName).Spec.Template.Spec.Containers[0]
+				g.Expect(container.Env).To(ContainElements(
+					corev1.EnvVar{Name: "NBRemote", Value: "tcp:ovsdbserver-nb-0." + namespace + ".svc.cluster.local:6641"},
+					corev1.EnvVar{Name: "NBGlobalOptions", Value: "northd-backoff-interval-ms=200 use_parallel_build=true"},
+				))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdNBGlobalReadyCondition,
+				corev1.ConditionFalse,
+			)
+
+			th.SimulateJobSuccess(jobName)
+			th.ExpectCondition(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdNBGlobalReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		It("applies the NB_Global options again when they drifted", func() {
+			cmd := ovnnorthd.NBGlobalOptionsCommand(
+				GetOVNNorthd(ovnNorthdName), "tcp:ovsdbserver-nb-0."+namespace+".svc.cluster.local:6641")
+			podExecutor.SetOutput(pod, cmd, "northd-backoff-interval-ms=200 use_parallel_build=true\n")
+			DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, map[string]string{"service": "ovn-northd"}, "ovn-northd"))
+			th.SimulateJobSuccess(jobName)
+			th.ExpectCondition(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdNBGlobalReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(GetOVNNorthd(ovnNorthdName).Status.NBGlobalOptionsDrift).To(BeEmpty())
+
+			// options changed by hand are not looked for on every reconcile
+			podExecutor.SetOutput(pod, cmd, "northd-backoff-interval-ms=500\n")
+			// Change something just to call reconcile loop
+			AnnotatePod(pod, "test", "drift")
+			Consistently(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.NBGlobalOptionsDrift).To(BeEmpty())
+			}, timeout/2, interval).Should(Succeed())
+
+			// they are found by the next check
+			Eventually(func(g Gomega) {
+				ovnNorthd := GetOVNNorthd(ovnNorthdName)
+				ovnNorthd.Status.NBGlobalOptionsCheckTime = nil
+				g.Expect(k8sClient.Status().Update(ctx, ovnNorthd)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				status := GetOVNNorthd(ovnNorthdName).Status
+				g.Expect(status.NBGlobalOptionsDrift).To(Equal(
+					[]string{"northd-backoff-interval-ms=500", "use_parallel_build="}))
+				// the options found in NB_Global are reported, not the ones of the spec
+				g.Expect(status.NBGlobalOptions).To(Equal(map[string]string{"northd-backoff-interval-ms": "500"}))
+				g.Expect(th.GetJob(jobName).Status.Succeeded).To(BeZero())
+			}, timeout, interval).Should(Succeed())
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdNBGlobalReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNNorthdNBGlobalReadyDriftMessage,
+					"northd-backoff-interval-ms=500 use_parallel_build="),
+			)
+		})
+	})
+
+	When("OVNNorthd is created with NB_Global options", func() {
+		var ovnNorthdName types.NamespacedName
+		jobName := types.NamespacedName{Name: ovnnorthd.NBGlobalJobName}
+
+		BeforeEach(func() {
+			jobName.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNNorthdSpec()
+			spec.Tuning.ProbeInterval = ptr.To[int32](60000)
+			spec.NBGlobalOptions = map[string]string{
+				"mac_prefix":      "0a:00:00",
+				"svc_monitor_mac": "0a:00:00:00:00:01",
+			}
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+		})
+
+		getJobOptions := func() string {
+			for _, envVar := range th.GetJob(jobName).Spec.Template.Spec.Containers[0].Env {
+				if envVar.Name == "NBGlobalOptions" {
+					return envVar.Value
+				}
+			}
+			return ""
+		}
+
+		It("applies them together with the tuning options and reports them", func() {
+			Eventually(func(g Gomega) {
+				g.Expect(getJobOptions()).To(Equal(
+					"mac_prefix=0a:00:00 northd_probe_interval=60000 svc_monitor_mac=0a:00:00:00:00:01"))
+			}, timeout, interval).Should(Succeed())
+			Expect(GetOVNNorthd(ovnNorthdName).Status.NBGlobalOptions).To(BeEmpty())
+
+			th.SimulateJobSuccess(jobName)
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.NBGlobalOptions).To(Equal(map[string]string{
+					"mac_prefix":            "0a:00:00",
+					"northd_probe_interval": "60000",
+					"svc_monitor_mac":       "0a:00:00:00:00:01",
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("applies them again when an option is removed", func() {
+			th.SimulateJobSuccess(jobName)
+			Eventually(func(g Gomega) {
+				ovnNorthd := GetOVNNorthd(ovnNorthdName)
+				delete(ovnNorthd.Spec.NBGlobalOptions, "svc_monitor_mac")
+				g.Expect(k8sClient.Update(ctx, ovnNorthd)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(getJobOptions()).To(Equal("mac_prefix=0a:00:00 northd_probe_interval=60000"))
+			}, timeout, interval).Should(Succeed())
+			th.SimulateJobSuccess(jobName)
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.NBGlobalOptions).To(Equal(map[string]string{
+					"mac_prefix":            "0a:00:00",
+					"northd_probe_interval": "60000",
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rejects an option set by the tuning", func() {
+			Eventually(func(g Gomega) {
+				ovnNorthd := GetOVNNorthd(ovnNorthdName)
+				ovnNorthd.Spec.NBGlobalOptions["northd_probe_interval"] = "5000"
+				err := k8sClient.Update(ctx, ovnNorthd)
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.nbGlobalOptions[northd_probe_interval]: Forbidden"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNNorthd is created with an endpoint mode", func() {
+		var ovnNorthdName types.NamespacedName
+		deplName := types.NamespacedName{Name: "ovn-northd"}
+		var spec ovnv1.OVNNorthdSpec
+
+		BeforeEach(func() {
+			deplName.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec = GetDefaultOVNNorthdSpec()
+		})
+
+		expectEndpoints := func(nbEndpoint string, sbEndpoint string) {
+			Eventually(func(g Gomega) {
+				args := th.GetDeployment(deplName).Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).To(ContainElements("--ovnnb-db="+nbEndpoint, "--ovnsb-db="+sbEndpoint))
+				status := GetOVNNorthd(ovnNorthdName).Status
+				g.Expect(status.NBConnection).To(Equal(nbEndpoint))
+				g.Expect(status.SBConnection).To(Equal(sbEndpoint))
+			}, timeout, interval).Should(Succeed())
+		}
+
+		It("connects to each member of the DB clusters by default", func() {
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			Expect(GetOVNNorthd(ovnNorthdName).Spec.EndpointMode).To(Equal(ovnv1.OVNNorthdEndpointLeaderOnly))
+			expectEndpoints(
+				"tcp:ovsdbserver-nb-0."+namespace+".svc.cluster.local:6641",
+				"tcp:ovsdbserver-sb-0."+namespace+".svc.cluster.local:6642")
+		})
+
+		It("connects to the Service of the DB clusters with anyMember", func() {
+			spec.EndpointMode = ovnv1.OVNNorthdEndpointAnyMember
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			expectEndpoints(
+				"tcp:ovsdbserver-nb."+namespace+".svc:6641",
+				"tcp:ovsdbserver-sb."+namespace+".svc:6642")
+			// the Service resolves to followers too, ovn-northd still only stays connected to the leader
+			Eventually(func(g Gomega) {
+				args := th.GetDeployment(deplName).Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).NotTo(ContainElement("--no-leader-only"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("keeps the leader-only connections by default", func() {
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			Eventually(func(g Gomega) {
+				args := th.GetDeployment(deplName).Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).To(ContainElement(HavePrefix("--ovnsb-db=")))
+				g.Expect(args).NotTo(ContainElement("--no-leader-only"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("keeps a single instance holding the SB lock with anyMember", func() {
+			spec.EndpointMode = ovnv1.OVNNorthdEndpointAnyMember
+			spec.Replicas = ptr.To[int32](2)
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			Eventually(func(g Gomega) {
+				args := th.GetDeployment(deplName).Spec.Template.Spec.Containers[0].Args
+				g.Expect(args).To(ContainElement("--ovnsb-db=tcp:ovsdbserver-sb." + namespace + ".svc:6642"))
+				g.Expect(args).NotTo(ContainElement("--no-leader-only"))
+			}, timeout, interval).Should(Succeed())
+
+			// both replicas connect to the leader, which grants the SB lock to one of them
+			statusCmd := ovnnorthd.AppctlCommand("status")
+			for i, state := range []string{"active", "standby"} {
+				pod := types.NamespacedName{Namespace: namespace, Name: fmt.Sprintf("ovn-northd-%d", i)}
+				podExecutor.SetOutput(pod, statusCmd, "Status: "+state+"\n")
+				DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, map[string]string{"service": "ovn-northd"}, "ovn-northd"))
+			}
+			Eventually(func(g Gomega) {
+				status := GetOVNNorthd(ovnNorthdName).Status
+				g.Expect(ovnnorthd.GetActiveInstances(status.Instances)).To(Equal([]string{"ovn-northd-0"}))
+				g.Expect(status.ActiveInstance).To(Equal("ovn-northd-0"))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdActiveReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+	})
+
+	When("OVNNorthd is created before the DB clusters", func() {
+		var ovnNorthdName types.NamespacedName
+		deplName := types.NamespacedName{Name: "ovn-northd"}
+
+		BeforeEach(func() {
+			deplName.Namespace = namespace
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, GetDefaultOVNNorthdSpec())
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+		})
+
+		It("waits for the DB clusters and deploys once their endpoints are available", func() {
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNDBClusterReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNDBClusterReadyWaitingMessage, "failed to find DBCluster of type NB"),
+			)
+			th.AssertDeploymentDoesNotExist(deplName)
+
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			th.ExpectCondition(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNDBClusterReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Eventually(func(g Gomega) {
+				inputHash := GetOVNNorthd(ovnNorthdName).Status.Hash[common.InputHashName]
+				g.Expect(inputHash).ToNot(BeEmpty())
+				container := th.GetDeployment(deplName).Spec.Template.Spec.Containers[0]
+				g.Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "CONFIG_HASH", Value: inputHash}))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNNorthd is paused", func() {
+		var ovnNorthdName types.NamespacedName
+		pods := []types.NamespacedName{}
+		statusCmd := ovnnorthd.AppctlCommand("status")
+		northdLabels := map[string]string{"service": "ovn-northd"}
+
+		setStates := func(state string) {
+			for _, pod := range pods {
+				podExecutor.SetOutput(pod, statusCmd, "Status: "+state+"\n")
+			}
+		}
+
+		BeforeEach(func() {
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec := GetDefaultOVNNorthdSpec()
+			spec.Replicas = ptr.To[int32](2)
+			spec.Paused = true
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			pods = []types.NamespacedName{
+				{Namespace: namespace, Name: "ovn-northd-0"},
+				{Namespace: namespace, Name: "ovn-northd-1"},
+			}
+			for _, pod := range pods {
+				podExecutor.SetOutput(pod, ovnnorthd.AppctlCommand("pause"), "")
+				podExecutor.SetOutput(pod, ovnnorthd.AppctlCommand("resume"), "")
+			}
+		})
+
+		It("reports the instances which did not get paused", func() {
+			setStates("standby")
+			for _, pod := range pods {
+				DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, northdLabels, "ovn-northd"))
+			}
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdPausedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNNorthdPausedRunningMessage, "ovn-northd-0,ovn-northd-1"),
+			)
+			// Paused does not affect Ready
+			conditions := OVNNorthdConditionGetter(ovnNorthdName)
+			ready := conditions.Get(condition.ReadyCondition)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Message).NotTo(ContainSubstring("not paused yet"))
+		})
+
+		It("reports the instances which could not be queried apart", func() {
+			// no status output is set for ovn-northd-1, querying it fails
+			podExecutor.SetOutput(pods[0], statusCmd, "Status: paused\n")
+			for _, pod := range pods {
+				DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, northdLabels, "ovn-northd"))
+			}
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdPausedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNNorthdPausedUnknownMessage, "ovn-northd-1"),
+			)
+			Expect(GetOVNNorthd(ovnNorthdName).Status.Instances).To(Equal(map[string]string{
+				"ovn-northd-0": ovnnorthd.InstancePaused,
+				"ovn-northd-1": ovnnorthd.InstanceUnknown,
+			}))
+		})
+
+		It("reports Paused instead of the active instance and resumes them", func() {
+			setStates("paused")
+			for _, pod := range pods {
+				DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, northdLabels, "ovn-northd"))
+			}
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdPausedCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				fmt.Sprintf(ovnv1.OVNNorthdPausedMessage, 2),
+			)
+			conditions := OVNNorthdConditionGetter(ovnNorthdName)
+			Expect(conditions.Has(ovnv1.OVNNorthdActiveReadyCondition)).To(BeFalse())
+
+			Eventually(func(g Gomega) {
+				ovnNorthd := GetOVNNorthd(ovnNorthdName)
+				ovnNorthd.Spec.Paused = false
+				g.Expect(k8sClient.Update(ctx, ovnNorthd)).Should(Succeed())
+			}, timeout, interval).Should(Succeed())
+			// the fake instances stay paused after resume
+			th.ExpectConditionWithDetails(
+				ovnNorthdName,
+				ConditionGetterFunc(OVNNorthdConditionGetter),
+				ovnv1.OVNNorthdPausedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				fmt.Sprintf(ovnv1.OVNNorthdResumeRunningMessage, "ovn-northd-0,ovn-northd-1"),
+			)
+
+			podExecutor.SetOutput(pods[0], statusCmd, "Status: active\n")
+			podExecutor.SetOutput(pods[1], statusCmd, "Status: standby\n")
+			AnnotatePod(pods[0], "test", "resumed")
+			Eventually(func(g Gomega) {
+				conditions := OVNNorthdConditionGetter(ovnNorthdName)
+				g.Expect(conditions.Has(ovnv1.OVNNorthdPausedCondition)).To(BeFalse())
+				g.Expect(conditions.IsTrue(ovnv1.OVNNorthdActiveReadyCondition)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	When("OVNNorthd is created with autoscaling", func() {
+		var ovnNorthdName types.NamespacedName
+		var spec ovnv1.OVNNorthdSpec
+		pod := types.NamespacedName{Name: "ovn-northd-0"}
+		deploymentName := types.NamespacedName{Name: "ovn-northd"}
+
+		BeforeEach(func() {
+			pod.Namespace = namespace
+			deploymentName.Namespace = namespace
+			dbs := CreateOVNDBClusters(namespace, map[string][]string{}, 1)
+			DeferCleanup(DeleteOVNDBClusters, dbs)
+			spec = GetDefaultOVNNorthdSpec()
+			spec.Autoscaling.MaxNThreads = ptr.To[int32](4)
+			spec.Autoscaling.MaxRequests = corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}
+		})
+
+		setOutputs := func(sbCells string, memoryKiB string) {
+			podExecutor.SetOutput(pod, ovnnorthd.AppctlCommand("status"), "Status: active\n")
+			podExecutor.SetOutput(pod, ovnnorthd.SBCellsCommand(),
+				"idl-cells-OVN_Northbound:20000 idl-cells-OVN_Southbound:"+sbCells+" idl-outstanding-txns-OVN_Southbound:0\n")
+			podExecutor.SetOutput(pod, ovnnorthd.MemoryUsageCommand(), memoryKiB)
+		}
+
+		createPod := func(sbCells string, memoryKiB string) {
+			setOutputs(sbCells, memoryKiB)
+			DeferCleanup(th.DeleteInstance, CreateRunningPod(pod, map[string]string{"service": "ovn-northd"}, "ovn-northd"))
+		}
+
+		It("deploys ovn-northd with the sizing in the auto mode", func() {
+			spec.Autoscaling.Mode = ovnv1.OVNNorthdAutoscalingAuto
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			// 1200000 SB DB cells and 400Mi of memory usage
+			createPod("1200000", "409600\n")
+
+			Eventually(func(g Gomega) {
+				status := GetOVNNorthd(ovnNorthdName).Status.Autoscaling
+				g.Expect(status).NotTo(BeNil())
+				g.Expect(status.SBCells).To(Equal(int64(1200000)))
+				g.Expect(status.MemoryUsage.String()).To(Equal("400Mi"))
+				g.Expect(status.Instance).To(Equal("ovn-northd-0"))
+				g.Expect(status.NThreads).To(Equal(int32(3)))
+				g.Expect(status.Requests.Cpu().String()).To(Equal("1500m"))
+				g.Expect(status.Requests.Memory().String()).To(Equal("500Mi"))
+				g.Expect(status.Applied).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				container := th.GetDeployment(deploymentName).Spec.Template.Spec.Containers[0]
+				g.Expect(container.Args).To(ContainElement("--n-threads=3"))
+				g.Expect(container.Resources.Requests.Cpu().String()).To(Equal("1500m"))
+				g.Expect(container.Resources.Requests.Memory().String()).To(Equal("500Mi"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("only records the sizing within the bounds in the recommend mode", func() {
+			spec.Autoscaling.Mode = ovnv1.OVNNorthdAutoscalingRecommend
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			// 6000000 SB DB cells and 2Gi of memory usage
+			createPod("6000000", "2097152\n")
+
+			Eventually(func(g Gomega) {
+				status := GetOVNNorthd(ovnNorthdName).Status.Autoscaling
+				g.Expect(status).NotTo(BeNil())
+				g.Expect(status.NThreads).To(Equal(int32(4)))
+				g.Expect(status.Requests.Memory().String()).To(Equal("1Gi"))
+				g.Expect(status.Applied).To(BeFalse())
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				container := th.GetDeployment(deploymentName).Spec.Template.Spec.Containers[0]
+				g.Expect(container.Args).To(ContainElement("--n-threads=1"))
+				g.Expect(container.Resources.Requests).To(BeEmpty())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("does not collect the inputs of an instance which just started", func() {
+			spec.Autoscaling.Mode = ovnv1.OVNNorthdAutoscalingAuto
+			ovnNorthdName = ovn.CreateOVNNorthd(namespace, spec)
+			DeferCleanup(ovn.DeleteOVNNorthd, ovnNorthdName)
+			setOutputs("1200000", "409600\n")
+			northdPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+					Labels:    map[string]string{"service": "ovn-northd"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "ovn-northd", Image: "test"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, northdPod)).Should(Succeed())
+			DeferCleanup(th.DeleteInstance, northdPod)
+			// running since now, in a single update
+			northdPod.Status.Phase = corev1.PodRunning
+			northdPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name: "ovn-northd",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()},
+				},
+			}}
+			Expect(k8sClient.Status().Update(ctx, northdPod)).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.ActiveInstance).To(Equal("ovn-northd-0"))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(GetOVNNorthd(ovnNorthdName).Status.Autoscaling).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
 })
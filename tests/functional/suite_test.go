@@ -52,6 +52,7 @@ import (
 	ovn_test "github.com/openstack-k8s-operators/ovn-operator/api/test/helpers"
 	ovnv1 "github.com/openstack-k8s-operators/ovn-operator/api/v1beta1"
 	"github.com/openstack-k8s-operators/ovn-operator/controllers"
+	ovn_common "github.com/openstack-k8s-operators/ovn-operator/pkg/common"
 
 	common_test "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
 	//+kubebuilder:scaffold:imports
@@ -70,6 +71,8 @@ var (
 	th        *common_test.TestHelper
 	ovn       *ovn_test.TestHelper
 	namespace string
+	// podExecutor - answers the commands the reconcilers run in the pods
+	podExecutor *FakePodExecutor
 )
 
 const (
@@ -163,30 +166,43 @@ var _ = BeforeSuite(func() {
 				CertDir: webhookInstallOptions.LocalServingCertDir,
 			}),
 		LeaderElection: false,
+		Cache:          ovn_common.CacheOptions(),
 	})
 	Expect(err).ToNot(HaveOccurred())
 
 	kclient, err := kubernetes.NewForConfig(cfg)
 	Expect(err).ToNot(HaveOccurred(), "failed to create kclient")
 
+	podExecutor = NewFakePodExecutor()
 	err = (&controllers.OVNNorthdReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		Kclient: kclient,
+		Client:      k8sManager.GetClient(),
+		Scheme:      k8sManager.GetScheme(),
+		Kclient:     kclient,
+		PodExecutor: podExecutor,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&controllers.OVNDBClusterReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		Kclient: kclient,
+		Client:      k8sManager.GetClient(),
+		Scheme:      k8sManager.GetScheme(),
+		Kclient:     kclient,
+		PodExecutor: podExecutor,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&controllers.OVNControllerReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		Kclient: kclient,
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Kclient:  kclient,
+		Recorder: k8sManager.GetEventRecorderFor("ovncontroller-controller"),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&controllers.OVNDiagnosticsReconciler{
+		Client:      k8sManager.GetClient(),
+		Scheme:      k8sManager.GetScheme(),
+		Kclient:     kclient,
+		PodExecutor: podExecutor,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 